@@ -0,0 +1,378 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: news/v1/service.proto
+
+package newsv1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	v1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// NewsServiceName is the fully-qualified name of the NewsService service.
+	NewsServiceName = "news.v1.NewsService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// NewsServiceCreateNewsProcedure is the fully-qualified name of the NewsService's CreateNews RPC.
+	NewsServiceCreateNewsProcedure = "/news.v1.NewsService/CreateNews"
+	// NewsServiceGetNewsProcedure is the fully-qualified name of the NewsService's GetNews RPC.
+	NewsServiceGetNewsProcedure = "/news.v1.NewsService/GetNews"
+	// NewsServiceBulkCreateNewsProcedure is the fully-qualified name of the NewsService's
+	// BulkCreateNews RPC.
+	NewsServiceBulkCreateNewsProcedure = "/news.v1.NewsService/BulkCreateNews"
+	// NewsServiceGetAllProcedure is the fully-qualified name of the NewsService's GetAll RPC.
+	NewsServiceGetAllProcedure = "/news.v1.NewsService/GetAll"
+	// NewsServiceUpdateNewsProcedure is the fully-qualified name of the NewsService's UpdateNews RPC.
+	NewsServiceUpdateNewsProcedure = "/news.v1.NewsService/UpdateNews"
+	// NewsServiceDeleteNewsProcedure is the fully-qualified name of the NewsService's DeleteNews RPC.
+	NewsServiceDeleteNewsProcedure = "/news.v1.NewsService/DeleteNews"
+	// NewsServiceRestoreNewsProcedure is the fully-qualified name of the NewsService's RestoreNews RPC.
+	NewsServiceRestoreNewsProcedure = "/news.v1.NewsService/RestoreNews"
+	// NewsServicePurgeNewsProcedure is the fully-qualified name of the NewsService's PurgeNews RPC.
+	NewsServicePurgeNewsProcedure = "/news.v1.NewsService/PurgeNews"
+	// NewsServiceListNewsProcedure is the fully-qualified name of the NewsService's ListNews RPC.
+	NewsServiceListNewsProcedure = "/news.v1.NewsService/ListNews"
+	// NewsServiceWatchNewsProcedure is the fully-qualified name of the NewsService's WatchNews RPC.
+	NewsServiceWatchNewsProcedure = "/news.v1.NewsService/WatchNews"
+)
+
+// NewsServiceClient is a client for the news.v1.NewsService service.
+type NewsServiceClient interface {
+	CreateNews(context.Context, *connect.Request[v1.CreateNewsRequest]) (*connect.Response[v1.CreateNewsResponse], error)
+	GetNews(context.Context, *connect.Request[v1.GetNewsRequest]) (*connect.Response[v1.GetNewsResponse], error)
+	// BulkCreateNews creates a stream of news in one call. Invalid items are
+	// reported per item instead of failing the call.
+	BulkCreateNews(context.Context) *connect.ClientStreamForClient[v1.BulkCreateNewsRequest, v1.BulkCreateNewsResponse]
+	// GetAll streams every live news. Prefer ListNews, which pages and filters.
+	GetAll(context.Context, *connect.Request[emptypb.Empty]) (*connect.ServerStreamForClient[v1.GetNewsResponse], error)
+	UpdateNews(context.Context, *connect.Request[v1.UpdateNewsRequest]) (*connect.Response[v1.UpdateNewsResponse], error)
+	// DeleteNews soft deletes the news, leaving a tombstone that RestoreNews can undo.
+	DeleteNews(context.Context, *connect.Request[v1.DeleteNewsRequest]) (*connect.Response[v1.DeleteNewsResponse], error)
+	RestoreNews(context.Context, *connect.Request[v1.RestoreNewsRequest]) (*connect.Response[v1.RestoreNewsResponse], error)
+	// PurgeNews removes the news permanently, whether or not it was deleted.
+	PurgeNews(context.Context, *connect.Request[v1.PurgeNewsRequest]) (*connect.Response[v1.PurgeNewsResponse], error)
+	ListNews(context.Context, *connect.Request[v1.ListNewsRequest]) (*connect.Response[v1.ListNewsResponse], error)
+	// WatchNews streams changes as they happen. Watchers that fall too far
+	// behind are disconnected with ABORTED and should resume from their last
+	// resume_token.
+	WatchNews(context.Context, *connect.Request[v1.WatchNewsRequest]) (*connect.ServerStreamForClient[v1.WatchNewsResponse], error)
+}
+
+// NewNewsServiceClient constructs a client for the news.v1.NewsService service. By default, it uses
+// the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewNewsServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) NewsServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	newsServiceMethods := v1.File_news_v1_service_proto.Services().ByName("NewsService").Methods()
+	return &newsServiceClient{
+		createNews: connect.NewClient[v1.CreateNewsRequest, v1.CreateNewsResponse](
+			httpClient,
+			baseURL+NewsServiceCreateNewsProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("CreateNews")),
+			connect.WithClientOptions(opts...),
+		),
+		getNews: connect.NewClient[v1.GetNewsRequest, v1.GetNewsResponse](
+			httpClient,
+			baseURL+NewsServiceGetNewsProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("GetNews")),
+			connect.WithClientOptions(opts...),
+		),
+		bulkCreateNews: connect.NewClient[v1.BulkCreateNewsRequest, v1.BulkCreateNewsResponse](
+			httpClient,
+			baseURL+NewsServiceBulkCreateNewsProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("BulkCreateNews")),
+			connect.WithClientOptions(opts...),
+		),
+		getAll: connect.NewClient[emptypb.Empty, v1.GetNewsResponse](
+			httpClient,
+			baseURL+NewsServiceGetAllProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("GetAll")),
+			connect.WithClientOptions(opts...),
+		),
+		updateNews: connect.NewClient[v1.UpdateNewsRequest, v1.UpdateNewsResponse](
+			httpClient,
+			baseURL+NewsServiceUpdateNewsProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("UpdateNews")),
+			connect.WithClientOptions(opts...),
+		),
+		deleteNews: connect.NewClient[v1.DeleteNewsRequest, v1.DeleteNewsResponse](
+			httpClient,
+			baseURL+NewsServiceDeleteNewsProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("DeleteNews")),
+			connect.WithClientOptions(opts...),
+		),
+		restoreNews: connect.NewClient[v1.RestoreNewsRequest, v1.RestoreNewsResponse](
+			httpClient,
+			baseURL+NewsServiceRestoreNewsProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("RestoreNews")),
+			connect.WithClientOptions(opts...),
+		),
+		purgeNews: connect.NewClient[v1.PurgeNewsRequest, v1.PurgeNewsResponse](
+			httpClient,
+			baseURL+NewsServicePurgeNewsProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("PurgeNews")),
+			connect.WithClientOptions(opts...),
+		),
+		listNews: connect.NewClient[v1.ListNewsRequest, v1.ListNewsResponse](
+			httpClient,
+			baseURL+NewsServiceListNewsProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("ListNews")),
+			connect.WithClientOptions(opts...),
+		),
+		watchNews: connect.NewClient[v1.WatchNewsRequest, v1.WatchNewsResponse](
+			httpClient,
+			baseURL+NewsServiceWatchNewsProcedure,
+			connect.WithSchema(newsServiceMethods.ByName("WatchNews")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// newsServiceClient implements NewsServiceClient.
+type newsServiceClient struct {
+	createNews     *connect.Client[v1.CreateNewsRequest, v1.CreateNewsResponse]
+	getNews        *connect.Client[v1.GetNewsRequest, v1.GetNewsResponse]
+	bulkCreateNews *connect.Client[v1.BulkCreateNewsRequest, v1.BulkCreateNewsResponse]
+	getAll         *connect.Client[emptypb.Empty, v1.GetNewsResponse]
+	updateNews     *connect.Client[v1.UpdateNewsRequest, v1.UpdateNewsResponse]
+	deleteNews     *connect.Client[v1.DeleteNewsRequest, v1.DeleteNewsResponse]
+	restoreNews    *connect.Client[v1.RestoreNewsRequest, v1.RestoreNewsResponse]
+	purgeNews      *connect.Client[v1.PurgeNewsRequest, v1.PurgeNewsResponse]
+	listNews       *connect.Client[v1.ListNewsRequest, v1.ListNewsResponse]
+	watchNews      *connect.Client[v1.WatchNewsRequest, v1.WatchNewsResponse]
+}
+
+// CreateNews calls news.v1.NewsService.CreateNews.
+func (c *newsServiceClient) CreateNews(ctx context.Context, req *connect.Request[v1.CreateNewsRequest]) (*connect.Response[v1.CreateNewsResponse], error) {
+	return c.createNews.CallUnary(ctx, req)
+}
+
+// GetNews calls news.v1.NewsService.GetNews.
+func (c *newsServiceClient) GetNews(ctx context.Context, req *connect.Request[v1.GetNewsRequest]) (*connect.Response[v1.GetNewsResponse], error) {
+	return c.getNews.CallUnary(ctx, req)
+}
+
+// BulkCreateNews calls news.v1.NewsService.BulkCreateNews.
+func (c *newsServiceClient) BulkCreateNews(ctx context.Context) *connect.ClientStreamForClient[v1.BulkCreateNewsRequest, v1.BulkCreateNewsResponse] {
+	return c.bulkCreateNews.CallClientStream(ctx)
+}
+
+// GetAll calls news.v1.NewsService.GetAll.
+func (c *newsServiceClient) GetAll(ctx context.Context, req *connect.Request[emptypb.Empty]) (*connect.ServerStreamForClient[v1.GetNewsResponse], error) {
+	return c.getAll.CallServerStream(ctx, req)
+}
+
+// UpdateNews calls news.v1.NewsService.UpdateNews.
+func (c *newsServiceClient) UpdateNews(ctx context.Context, req *connect.Request[v1.UpdateNewsRequest]) (*connect.Response[v1.UpdateNewsResponse], error) {
+	return c.updateNews.CallUnary(ctx, req)
+}
+
+// DeleteNews calls news.v1.NewsService.DeleteNews.
+func (c *newsServiceClient) DeleteNews(ctx context.Context, req *connect.Request[v1.DeleteNewsRequest]) (*connect.Response[v1.DeleteNewsResponse], error) {
+	return c.deleteNews.CallUnary(ctx, req)
+}
+
+// RestoreNews calls news.v1.NewsService.RestoreNews.
+func (c *newsServiceClient) RestoreNews(ctx context.Context, req *connect.Request[v1.RestoreNewsRequest]) (*connect.Response[v1.RestoreNewsResponse], error) {
+	return c.restoreNews.CallUnary(ctx, req)
+}
+
+// PurgeNews calls news.v1.NewsService.PurgeNews.
+func (c *newsServiceClient) PurgeNews(ctx context.Context, req *connect.Request[v1.PurgeNewsRequest]) (*connect.Response[v1.PurgeNewsResponse], error) {
+	return c.purgeNews.CallUnary(ctx, req)
+}
+
+// ListNews calls news.v1.NewsService.ListNews.
+func (c *newsServiceClient) ListNews(ctx context.Context, req *connect.Request[v1.ListNewsRequest]) (*connect.Response[v1.ListNewsResponse], error) {
+	return c.listNews.CallUnary(ctx, req)
+}
+
+// WatchNews calls news.v1.NewsService.WatchNews.
+func (c *newsServiceClient) WatchNews(ctx context.Context, req *connect.Request[v1.WatchNewsRequest]) (*connect.ServerStreamForClient[v1.WatchNewsResponse], error) {
+	return c.watchNews.CallServerStream(ctx, req)
+}
+
+// NewsServiceHandler is an implementation of the news.v1.NewsService service.
+type NewsServiceHandler interface {
+	CreateNews(context.Context, *connect.Request[v1.CreateNewsRequest]) (*connect.Response[v1.CreateNewsResponse], error)
+	GetNews(context.Context, *connect.Request[v1.GetNewsRequest]) (*connect.Response[v1.GetNewsResponse], error)
+	// BulkCreateNews creates a stream of news in one call. Invalid items are
+	// reported per item instead of failing the call.
+	BulkCreateNews(context.Context, *connect.ClientStream[v1.BulkCreateNewsRequest]) (*connect.Response[v1.BulkCreateNewsResponse], error)
+	// GetAll streams every live news. Prefer ListNews, which pages and filters.
+	GetAll(context.Context, *connect.Request[emptypb.Empty], *connect.ServerStream[v1.GetNewsResponse]) error
+	UpdateNews(context.Context, *connect.Request[v1.UpdateNewsRequest]) (*connect.Response[v1.UpdateNewsResponse], error)
+	// DeleteNews soft deletes the news, leaving a tombstone that RestoreNews can undo.
+	DeleteNews(context.Context, *connect.Request[v1.DeleteNewsRequest]) (*connect.Response[v1.DeleteNewsResponse], error)
+	RestoreNews(context.Context, *connect.Request[v1.RestoreNewsRequest]) (*connect.Response[v1.RestoreNewsResponse], error)
+	// PurgeNews removes the news permanently, whether or not it was deleted.
+	PurgeNews(context.Context, *connect.Request[v1.PurgeNewsRequest]) (*connect.Response[v1.PurgeNewsResponse], error)
+	ListNews(context.Context, *connect.Request[v1.ListNewsRequest]) (*connect.Response[v1.ListNewsResponse], error)
+	// WatchNews streams changes as they happen. Watchers that fall too far
+	// behind are disconnected with ABORTED and should resume from their last
+	// resume_token.
+	WatchNews(context.Context, *connect.Request[v1.WatchNewsRequest], *connect.ServerStream[v1.WatchNewsResponse]) error
+}
+
+// NewNewsServiceHandler builds an HTTP handler from the service implementation. It returns the path
+// on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewNewsServiceHandler(svc NewsServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	newsServiceMethods := v1.File_news_v1_service_proto.Services().ByName("NewsService").Methods()
+	newsServiceCreateNewsHandler := connect.NewUnaryHandler(
+		NewsServiceCreateNewsProcedure,
+		svc.CreateNews,
+		connect.WithSchema(newsServiceMethods.ByName("CreateNews")),
+		connect.WithHandlerOptions(opts...),
+	)
+	newsServiceGetNewsHandler := connect.NewUnaryHandler(
+		NewsServiceGetNewsProcedure,
+		svc.GetNews,
+		connect.WithSchema(newsServiceMethods.ByName("GetNews")),
+		connect.WithHandlerOptions(opts...),
+	)
+	newsServiceBulkCreateNewsHandler := connect.NewClientStreamHandler(
+		NewsServiceBulkCreateNewsProcedure,
+		svc.BulkCreateNews,
+		connect.WithSchema(newsServiceMethods.ByName("BulkCreateNews")),
+		connect.WithHandlerOptions(opts...),
+	)
+	newsServiceGetAllHandler := connect.NewServerStreamHandler(
+		NewsServiceGetAllProcedure,
+		svc.GetAll,
+		connect.WithSchema(newsServiceMethods.ByName("GetAll")),
+		connect.WithHandlerOptions(opts...),
+	)
+	newsServiceUpdateNewsHandler := connect.NewUnaryHandler(
+		NewsServiceUpdateNewsProcedure,
+		svc.UpdateNews,
+		connect.WithSchema(newsServiceMethods.ByName("UpdateNews")),
+		connect.WithHandlerOptions(opts...),
+	)
+	newsServiceDeleteNewsHandler := connect.NewUnaryHandler(
+		NewsServiceDeleteNewsProcedure,
+		svc.DeleteNews,
+		connect.WithSchema(newsServiceMethods.ByName("DeleteNews")),
+		connect.WithHandlerOptions(opts...),
+	)
+	newsServiceRestoreNewsHandler := connect.NewUnaryHandler(
+		NewsServiceRestoreNewsProcedure,
+		svc.RestoreNews,
+		connect.WithSchema(newsServiceMethods.ByName("RestoreNews")),
+		connect.WithHandlerOptions(opts...),
+	)
+	newsServicePurgeNewsHandler := connect.NewUnaryHandler(
+		NewsServicePurgeNewsProcedure,
+		svc.PurgeNews,
+		connect.WithSchema(newsServiceMethods.ByName("PurgeNews")),
+		connect.WithHandlerOptions(opts...),
+	)
+	newsServiceListNewsHandler := connect.NewUnaryHandler(
+		NewsServiceListNewsProcedure,
+		svc.ListNews,
+		connect.WithSchema(newsServiceMethods.ByName("ListNews")),
+		connect.WithHandlerOptions(opts...),
+	)
+	newsServiceWatchNewsHandler := connect.NewServerStreamHandler(
+		NewsServiceWatchNewsProcedure,
+		svc.WatchNews,
+		connect.WithSchema(newsServiceMethods.ByName("WatchNews")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/news.v1.NewsService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case NewsServiceCreateNewsProcedure:
+			newsServiceCreateNewsHandler.ServeHTTP(w, r)
+		case NewsServiceGetNewsProcedure:
+			newsServiceGetNewsHandler.ServeHTTP(w, r)
+		case NewsServiceBulkCreateNewsProcedure:
+			newsServiceBulkCreateNewsHandler.ServeHTTP(w, r)
+		case NewsServiceGetAllProcedure:
+			newsServiceGetAllHandler.ServeHTTP(w, r)
+		case NewsServiceUpdateNewsProcedure:
+			newsServiceUpdateNewsHandler.ServeHTTP(w, r)
+		case NewsServiceDeleteNewsProcedure:
+			newsServiceDeleteNewsHandler.ServeHTTP(w, r)
+		case NewsServiceRestoreNewsProcedure:
+			newsServiceRestoreNewsHandler.ServeHTTP(w, r)
+		case NewsServicePurgeNewsProcedure:
+			newsServicePurgeNewsHandler.ServeHTTP(w, r)
+		case NewsServiceListNewsProcedure:
+			newsServiceListNewsHandler.ServeHTTP(w, r)
+		case NewsServiceWatchNewsProcedure:
+			newsServiceWatchNewsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedNewsServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedNewsServiceHandler struct{}
+
+func (UnimplementedNewsServiceHandler) CreateNews(context.Context, *connect.Request[v1.CreateNewsRequest]) (*connect.Response[v1.CreateNewsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.CreateNews is not implemented"))
+}
+
+func (UnimplementedNewsServiceHandler) GetNews(context.Context, *connect.Request[v1.GetNewsRequest]) (*connect.Response[v1.GetNewsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.GetNews is not implemented"))
+}
+
+func (UnimplementedNewsServiceHandler) BulkCreateNews(context.Context, *connect.ClientStream[v1.BulkCreateNewsRequest]) (*connect.Response[v1.BulkCreateNewsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.BulkCreateNews is not implemented"))
+}
+
+func (UnimplementedNewsServiceHandler) GetAll(context.Context, *connect.Request[emptypb.Empty], *connect.ServerStream[v1.GetNewsResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.GetAll is not implemented"))
+}
+
+func (UnimplementedNewsServiceHandler) UpdateNews(context.Context, *connect.Request[v1.UpdateNewsRequest]) (*connect.Response[v1.UpdateNewsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.UpdateNews is not implemented"))
+}
+
+func (UnimplementedNewsServiceHandler) DeleteNews(context.Context, *connect.Request[v1.DeleteNewsRequest]) (*connect.Response[v1.DeleteNewsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.DeleteNews is not implemented"))
+}
+
+func (UnimplementedNewsServiceHandler) RestoreNews(context.Context, *connect.Request[v1.RestoreNewsRequest]) (*connect.Response[v1.RestoreNewsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.RestoreNews is not implemented"))
+}
+
+func (UnimplementedNewsServiceHandler) PurgeNews(context.Context, *connect.Request[v1.PurgeNewsRequest]) (*connect.Response[v1.PurgeNewsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.PurgeNews is not implemented"))
+}
+
+func (UnimplementedNewsServiceHandler) ListNews(context.Context, *connect.Request[v1.ListNewsRequest]) (*connect.Response[v1.ListNewsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.ListNews is not implemented"))
+}
+
+func (UnimplementedNewsServiceHandler) WatchNews(context.Context, *connect.Request[v1.WatchNewsRequest], *connect.ServerStream[v1.WatchNewsResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("news.v1.NewsService.WatchNews is not implemented"))
+}
@@ -20,9 +20,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	NewsService_CreateNews_FullMethodName = "/news.v1.NewsService/CreateNews"
-	NewsService_GetNews_FullMethodName    = "/news.v1.NewsService/GetNews"
-	NewsService_GetAll_FullMethodName     = "/news.v1.NewsService/GetAll"
+	NewsService_CreateNews_FullMethodName     = "/news.v1.NewsService/CreateNews"
+	NewsService_GetNews_FullMethodName        = "/news.v1.NewsService/GetNews"
+	NewsService_BulkCreateNews_FullMethodName = "/news.v1.NewsService/BulkCreateNews"
+	NewsService_GetAll_FullMethodName         = "/news.v1.NewsService/GetAll"
+	NewsService_UpdateNews_FullMethodName     = "/news.v1.NewsService/UpdateNews"
+	NewsService_DeleteNews_FullMethodName     = "/news.v1.NewsService/DeleteNews"
+	NewsService_RestoreNews_FullMethodName    = "/news.v1.NewsService/RestoreNews"
+	NewsService_PurgeNews_FullMethodName      = "/news.v1.NewsService/PurgeNews"
+	NewsService_ListNews_FullMethodName       = "/news.v1.NewsService/ListNews"
+	NewsService_WatchNews_FullMethodName      = "/news.v1.NewsService/WatchNews"
 )
 
 // NewsServiceClient is the client API for NewsService service.
@@ -31,7 +38,22 @@ const (
 type NewsServiceClient interface {
 	CreateNews(ctx context.Context, in *CreateNewsRequest, opts ...grpc.CallOption) (*CreateNewsResponse, error)
 	GetNews(ctx context.Context, in *GetNewsRequest, opts ...grpc.CallOption) (*GetNewsResponse, error)
+	// BulkCreateNews creates a stream of news in one call. Invalid items are
+	// reported per item instead of failing the call.
+	BulkCreateNews(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BulkCreateNewsRequest, BulkCreateNewsResponse], error)
+	// GetAll streams every live news. Prefer ListNews, which pages and filters.
 	GetAll(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetNewsResponse], error)
+	UpdateNews(ctx context.Context, in *UpdateNewsRequest, opts ...grpc.CallOption) (*UpdateNewsResponse, error)
+	// DeleteNews soft deletes the news, leaving a tombstone that RestoreNews can undo.
+	DeleteNews(ctx context.Context, in *DeleteNewsRequest, opts ...grpc.CallOption) (*DeleteNewsResponse, error)
+	RestoreNews(ctx context.Context, in *RestoreNewsRequest, opts ...grpc.CallOption) (*RestoreNewsResponse, error)
+	// PurgeNews removes the news permanently, whether or not it was deleted.
+	PurgeNews(ctx context.Context, in *PurgeNewsRequest, opts ...grpc.CallOption) (*PurgeNewsResponse, error)
+	ListNews(ctx context.Context, in *ListNewsRequest, opts ...grpc.CallOption) (*ListNewsResponse, error)
+	// WatchNews streams changes as they happen. Watchers that fall too far
+	// behind are disconnected with ABORTED and should resume from their last
+	// resume_token.
+	WatchNews(ctx context.Context, in *WatchNewsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchNewsResponse], error)
 }
 
 type newsServiceClient struct {
@@ -62,9 +84,22 @@ func (c *newsServiceClient) GetNews(ctx context.Context, in *GetNewsRequest, opt
 	return out, nil
 }
 
+func (c *newsServiceClient) BulkCreateNews(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BulkCreateNewsRequest, BulkCreateNewsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NewsService_ServiceDesc.Streams[0], NewsService_BulkCreateNews_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BulkCreateNewsRequest, BulkCreateNewsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NewsService_BulkCreateNewsClient = grpc.ClientStreamingClient[BulkCreateNewsRequest, BulkCreateNewsResponse]
+
 func (c *newsServiceClient) GetAll(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetNewsResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &NewsService_ServiceDesc.Streams[0], NewsService_GetAll_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &NewsService_ServiceDesc.Streams[1], NewsService_GetAll_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -81,13 +116,97 @@ func (c *newsServiceClient) GetAll(ctx context.Context, in *emptypb.Empty, opts
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type NewsService_GetAllClient = grpc.ServerStreamingClient[GetNewsResponse]
 
+func (c *newsServiceClient) UpdateNews(ctx context.Context, in *UpdateNewsRequest, opts ...grpc.CallOption) (*UpdateNewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateNewsResponse)
+	err := c.cc.Invoke(ctx, NewsService_UpdateNews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *newsServiceClient) DeleteNews(ctx context.Context, in *DeleteNewsRequest, opts ...grpc.CallOption) (*DeleteNewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteNewsResponse)
+	err := c.cc.Invoke(ctx, NewsService_DeleteNews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *newsServiceClient) RestoreNews(ctx context.Context, in *RestoreNewsRequest, opts ...grpc.CallOption) (*RestoreNewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreNewsResponse)
+	err := c.cc.Invoke(ctx, NewsService_RestoreNews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *newsServiceClient) PurgeNews(ctx context.Context, in *PurgeNewsRequest, opts ...grpc.CallOption) (*PurgeNewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeNewsResponse)
+	err := c.cc.Invoke(ctx, NewsService_PurgeNews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *newsServiceClient) ListNews(ctx context.Context, in *ListNewsRequest, opts ...grpc.CallOption) (*ListNewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNewsResponse)
+	err := c.cc.Invoke(ctx, NewsService_ListNews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *newsServiceClient) WatchNews(ctx context.Context, in *WatchNewsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchNewsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NewsService_ServiceDesc.Streams[2], NewsService_WatchNews_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchNewsRequest, WatchNewsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NewsService_WatchNewsClient = grpc.ServerStreamingClient[WatchNewsResponse]
+
 // NewsServiceServer is the server API for NewsService service.
 // All implementations must embed UnimplementedNewsServiceServer
 // for forward compatibility.
 type NewsServiceServer interface {
 	CreateNews(context.Context, *CreateNewsRequest) (*CreateNewsResponse, error)
 	GetNews(context.Context, *GetNewsRequest) (*GetNewsResponse, error)
+	// BulkCreateNews creates a stream of news in one call. Invalid items are
+	// reported per item instead of failing the call.
+	BulkCreateNews(grpc.ClientStreamingServer[BulkCreateNewsRequest, BulkCreateNewsResponse]) error
+	// GetAll streams every live news. Prefer ListNews, which pages and filters.
 	GetAll(*emptypb.Empty, grpc.ServerStreamingServer[GetNewsResponse]) error
+	UpdateNews(context.Context, *UpdateNewsRequest) (*UpdateNewsResponse, error)
+	// DeleteNews soft deletes the news, leaving a tombstone that RestoreNews can undo.
+	DeleteNews(context.Context, *DeleteNewsRequest) (*DeleteNewsResponse, error)
+	RestoreNews(context.Context, *RestoreNewsRequest) (*RestoreNewsResponse, error)
+	// PurgeNews removes the news permanently, whether or not it was deleted.
+	PurgeNews(context.Context, *PurgeNewsRequest) (*PurgeNewsResponse, error)
+	ListNews(context.Context, *ListNewsRequest) (*ListNewsResponse, error)
+	// WatchNews streams changes as they happen. Watchers that fall too far
+	// behind are disconnected with ABORTED and should resume from their last
+	// resume_token.
+	WatchNews(*WatchNewsRequest, grpc.ServerStreamingServer[WatchNewsResponse]) error
 	mustEmbedUnimplementedNewsServiceServer()
 }
 
@@ -104,9 +223,30 @@ func (UnimplementedNewsServiceServer) CreateNews(context.Context, *CreateNewsReq
 func (UnimplementedNewsServiceServer) GetNews(context.Context, *GetNewsRequest) (*GetNewsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetNews not implemented")
 }
+func (UnimplementedNewsServiceServer) BulkCreateNews(grpc.ClientStreamingServer[BulkCreateNewsRequest, BulkCreateNewsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method BulkCreateNews not implemented")
+}
 func (UnimplementedNewsServiceServer) GetAll(*emptypb.Empty, grpc.ServerStreamingServer[GetNewsResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method GetAll not implemented")
 }
+func (UnimplementedNewsServiceServer) UpdateNews(context.Context, *UpdateNewsRequest) (*UpdateNewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNews not implemented")
+}
+func (UnimplementedNewsServiceServer) DeleteNews(context.Context, *DeleteNewsRequest) (*DeleteNewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNews not implemented")
+}
+func (UnimplementedNewsServiceServer) RestoreNews(context.Context, *RestoreNewsRequest) (*RestoreNewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreNews not implemented")
+}
+func (UnimplementedNewsServiceServer) PurgeNews(context.Context, *PurgeNewsRequest) (*PurgeNewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeNews not implemented")
+}
+func (UnimplementedNewsServiceServer) ListNews(context.Context, *ListNewsRequest) (*ListNewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNews not implemented")
+}
+func (UnimplementedNewsServiceServer) WatchNews(*WatchNewsRequest, grpc.ServerStreamingServer[WatchNewsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchNews not implemented")
+}
 func (UnimplementedNewsServiceServer) mustEmbedUnimplementedNewsServiceServer() {}
 func (UnimplementedNewsServiceServer) testEmbeddedByValue()                     {}
 
@@ -164,6 +304,13 @@ func _NewsService_GetNews_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NewsService_BulkCreateNews_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NewsServiceServer).BulkCreateNews(&grpc.GenericServerStream[BulkCreateNewsRequest, BulkCreateNewsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NewsService_BulkCreateNewsServer = grpc.ClientStreamingServer[BulkCreateNewsRequest, BulkCreateNewsResponse]
+
 func _NewsService_GetAll_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(emptypb.Empty)
 	if err := stream.RecvMsg(m); err != nil {
@@ -175,6 +322,107 @@ func _NewsService_GetAll_Handler(srv interface{}, stream grpc.ServerStream) erro
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type NewsService_GetAllServer = grpc.ServerStreamingServer[GetNewsResponse]
 
+func _NewsService_UpdateNews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NewsServiceServer).UpdateNews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NewsService_UpdateNews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NewsServiceServer).UpdateNews(ctx, req.(*UpdateNewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NewsService_DeleteNews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NewsServiceServer).DeleteNews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NewsService_DeleteNews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NewsServiceServer).DeleteNews(ctx, req.(*DeleteNewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NewsService_RestoreNews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreNewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NewsServiceServer).RestoreNews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NewsService_RestoreNews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NewsServiceServer).RestoreNews(ctx, req.(*RestoreNewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NewsService_PurgeNews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeNewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NewsServiceServer).PurgeNews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NewsService_PurgeNews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NewsServiceServer).PurgeNews(ctx, req.(*PurgeNewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NewsService_ListNews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NewsServiceServer).ListNews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NewsService_ListNews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NewsServiceServer).ListNews(ctx, req.(*ListNewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NewsService_WatchNews_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchNewsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NewsServiceServer).WatchNews(m, &grpc.GenericServerStream[WatchNewsRequest, WatchNewsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NewsService_WatchNewsServer = grpc.ServerStreamingServer[WatchNewsResponse]
+
 // NewsService_ServiceDesc is the grpc.ServiceDesc for NewsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -190,13 +438,43 @@ var NewsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetNews",
 			Handler:    _NewsService_GetNews_Handler,
 		},
+		{
+			MethodName: "UpdateNews",
+			Handler:    _NewsService_UpdateNews_Handler,
+		},
+		{
+			MethodName: "DeleteNews",
+			Handler:    _NewsService_DeleteNews_Handler,
+		},
+		{
+			MethodName: "RestoreNews",
+			Handler:    _NewsService_RestoreNews_Handler,
+		},
+		{
+			MethodName: "PurgeNews",
+			Handler:    _NewsService_PurgeNews_Handler,
+		},
+		{
+			MethodName: "ListNews",
+			Handler:    _NewsService_ListNews_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BulkCreateNews",
+			Handler:       _NewsService_BulkCreateNews_Handler,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "GetAll",
 			Handler:       _NewsService_GetAll_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchNews",
+			Handler:       _NewsService_WatchNews_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "news/v1/service.proto",
 }
@@ -2,18 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
-	"github.com/sabuhigr/grpc-demo/types"
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"github.com/sabuhigr/grpc-demo/internal/certs"
+	"github.com/sabuhigr/grpc-demo/internal/config"
+	"github.com/sabuhigr/grpc-demo/internal/tracing"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
-	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
@@ -21,11 +30,6 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-func init() {
-	// Configure log package as json
-	log.SetFormatter(&log.JSONFormatter{PrettyPrint: true})
-}
-
 func myUnaryInterceptor(
 	ctx context.Context,
 	method string,
@@ -39,35 +43,122 @@ func myUnaryInterceptor(
 	return invoker(ctx, method, req, reply, cc, opts...)
 }
 
+// transportCredentials returns TLS credentials when a CA or client
+// certificate is configured, plaintext otherwise.
+func transportCredentials(cfg config.ClientTLS) (credentials.TransportCredentials, error) {
+	if cfg.CA == "" && cfg.Cert == "" {
+		return insecure.NewCredentials(), nil
+	}
+	reloader, err := certs.NewReloader(certs.Files{Cert: cfg.Cert, Key: cfg.Key, CA: cfg.CA})
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(certs.ClientConfig(reloader, cfg.ServerName)), nil
+}
+
+// bearerToken returns the configured token, read from a file or minted with
+// a local key, in that order of preference. It is empty when a client
+// certificate is used instead.
+func bearerToken(cfg config.ClientAuth, clientCert string) (string, error) {
+	switch {
+	case cfg.Token != "":
+		return cfg.Token, nil
+	case cfg.TokenFile != "":
+		raw, err := os.ReadFile(cfg.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(raw)), nil
+	case cfg.JWTKey != "":
+		key, err := auth.LoadSigningKey(cfg.JWTKey)
+		if err != nil {
+			return "", err
+		}
+		now := time.Now()
+		claims := jwt.MapClaims{
+			"sub": cfg.JWTSubject,
+			"iat": now.Unix(),
+			"nbf": now.Unix(),
+			"exp": now.Add(cfg.JWTTTL).Unix(),
+		}
+		if len(cfg.JWTRoles) > 0 {
+			claims[auth.RolesClaim] = []string(cfg.JWTRoles)
+		}
+		if cfg.JWTIssuer != "" {
+			claims["iss"] = cfg.JWTIssuer
+		}
+		if cfg.JWTAudience != "" {
+			claims["aud"] = cfg.JWTAudience
+		}
+		return auth.MintJWT(key, cfg.JWTKeyID, claims)
+	case clientCert != "":
+		// The client certificate identifies the caller.
+		return "", nil
+	default:
+		return "", errors.New("one of -token, -token-file, -jwt-key or -tls-cert is required")
+	}
+}
+
 func main() {
-	md := metadata.New(map[string]string{
-		"authorization": types.Static_token,
-	})
+	cfg := config.DefaultClient()
+	if err := config.Load(os.Args[0], config.ClientEnvPrefix, os.Args[1:], cfg); err != nil {
+		log.Fatalf("failed to load configuration: %v", err)
+	}
+	cfg.Logging.Apply()
+
+	bearer, err := bearerToken(cfg.Auth, cfg.TLS.Cert)
+	if err != nil {
+		log.Fatalf("failed to get a token: %v", err)
+	}
+	md := metadata.New(map[string]string{})
+	if bearer != "" {
+		md.Set("authorization", "Bearer "+bearer)
+	}
+
+	//context with the configured timeout
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
 
-	//context with 10 second timeout
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.Options("news-client"))
+	if err != nil {
+		log.Fatalf("failed to configure tracing: %v", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Errorf("failed to flush traces: %v", err)
+		}
+	}()
+	// Every call of this run belongs to one trace.
+	ctx, span := otel.Tracer("github.com/sabuhigr/grpc-demo/cmd/client").Start(ctx, "client.run")
+	defer span.End()
 	customctx := metadata.NewOutgoingContext(ctx, md)
 
+	creds, err := transportCredentials(cfg.TLS)
+	if err != nil {
+		log.Fatalf("failed to configure TLS: %v", err)
+	}
+
 	conn, err := grpc.NewClient(
-		"127.0.0.1:8080",
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		cfg.Addr,
+		grpc.WithTransportCredentials(creds),
 		grpc.WithDefaultServiceConfig(`{"load_balancing_config": {"pick_first":{}}}`), //grpc.WithDefaultServiceConfig(`{"load_balancing_config": {"round_robin":{}}}`)
 		grpc.WithConnectParams(
 			grpc.ConnectParams{
 				Backoff: backoff.Config{
-					BaseDelay:  1 * time.Second,
-					Multiplier: 1.6,
-					MaxDelay:   120 * time.Second,
+					BaseDelay:  cfg.Backoff.BaseDelay,
+					Multiplier: cfg.Backoff.Multiplier,
+					MaxDelay:   cfg.Backoff.MaxDelay,
 				},
-				MinConnectTimeout: 5 * time.Second,
+				MinConnectTimeout: cfg.Backoff.MinConnectTimeout,
 			},
 		),
 		grpc.WithUnaryInterceptor(myUnaryInterceptor),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		grpc.WithKeepaliveParams(
 			keepalive.ClientParameters{
-				Time:                10 * time.Second,
-				Timeout:             5 * time.Second,
-				PermitWithoutStream: true,
+				Time:                cfg.Keepalive.Time,
+				Timeout:             cfg.Keepalive.Timeout,
+				PermitWithoutStream: cfg.Keepalive.PermitWithoutStream,
 			}),
 	)
 
@@ -78,79 +169,66 @@ func main() {
 	client := newsv1.NewNewsServiceClient(conn)
 
 	log.Info("Starting to bulk creating news")
+	bulk, err := client.BulkCreateNews(customctx)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Errorf("failed to start bulk create")
+		return
+	}
+
 	for i := range 5 {
-		_, err := client.CreateNews(customctx, &newsv1.CreateNewsRequest{
-			Id:      uuid.New().String(),
-			Author:  fmt.Sprintf("Test Author %v", i),
-			Title:   "Test",
-			Summary: "Test",
-			Content: "Test",
-			Source:  "https://sabuhi.grpc.github.io",
-			Tags:    []string{"Test"},
-		})
+		if err := bulk.Send(&newsv1.BulkCreateNewsRequest{
+			News: &newsv1.CreateNewsRequest{
+				Id:      uuid.New().String(),
+				Author:  fmt.Sprintf("Test Author %v", i),
+				Title:   "Test",
+				Summary: "Test",
+				Content: "Test",
+				Source:  "https://sabuhi.grpc.github.io",
+				Tags:    []string{"Test"},
+			},
+		}); err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Errorf("failed to send news")
+			return
+		}
+	}
 
-		if err != nil {
-			if status.Code(err) != codes.InvalidArgument {
-				log.WithFields(log.Fields{
-					"error": err,
-				}).Errorf("failed to get all news")
-				return
-			} else {
-				for _, d := range status.Convert(err).Details() {
-					switch info := d.(type) {
-					case *errdetails.QuotaFailure:
-						log.Errorf("Quota failure: %s", info)
-					case *errdetails.PreconditionFailure:
-						detail := d.(*errdetails.PreconditionFailure).Violations
-						for _, v1 := range detail {
-							log.Info(fmt.Sprintf("details: %+v", v1))
-						}
-					case *errdetails.ResourceInfo:
-						log.Infof("ResourceInfo: %s", info)
-					case *errdetails.BadRequest:
-						log.Infof("ResourceInfo: %s", info)
-					default:
-						log.Infof("Unexpected type: %s", info)
-					}
-				}
+	bulkRes, err := bulk.CloseAndRecv()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Errorf("failed to bulk create news")
+		logErrorDetails(err)
+		return
+	}
 
-			}
+	for _, result := range bulkRes.Results {
+		if result.GetError() != nil {
+			itemErr := status.FromProto(result.GetError()).Err()
+			log.WithFields(log.Fields{
+				"index": result.Index,
+				"error": itemErr,
+			}).Errorf("failed to create news")
+			logErrorDetails(itemErr)
 		}
 	}
 
 	log.Infof(
-		"Bulk news created successfully",
+		"Bulk news created successfully: %d of %d", bulkRes.CreatedCount, len(bulkRes.Results),
 	)
 
 	log.Info("Starting to get all news")
 
 	allnewsRes, err := client.GetAll(customctx, &emptypb.Empty{})
 	if err != nil {
-		if status.Code(err) != codes.InvalidArgument {
-			log.WithFields(log.Fields{
-				"error": err,
-			}).Errorf("failed to get all news")
-			return
-		} else {
-			for _, d := range status.Convert(err).Details() {
-				switch info := d.(type) {
-				case *errdetails.QuotaFailure:
-					log.Errorf("Quota failure: %s", info)
-				case *errdetails.PreconditionFailure:
-					detail := d.(*errdetails.PreconditionFailure).Violations
-					for _, v1 := range detail {
-						log.Info(fmt.Sprintf("details: %+v", v1))
-					}
-				case *errdetails.ResourceInfo:
-					log.Infof("ResourceInfo: %s", info)
-				case *errdetails.BadRequest:
-					log.Infof("ResourceInfo: %s", info)
-				default:
-					log.Infof("Unexpected type: %s", info)
-				}
-			}
-
-		}
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Errorf("failed to get all news")
+		logErrorDetails(err)
+		return
 	}
 
 	allNews := make([]*newsv1.GetNewsResponse, 0)
@@ -174,3 +252,31 @@ func main() {
 		log.Infof("Got author [%v] news successfully.ID - %v", resp.Author, resp.Id)
 	}
 }
+
+// logErrorDetails logs the rich error details attached to a gRPC status.
+func logErrorDetails(err error) {
+	for _, d := range status.Convert(err).Details() {
+		switch info := d.(type) {
+		case *errdetails.QuotaFailure:
+			log.Errorf("Quota failure: %s", info)
+		case *errdetails.PreconditionFailure:
+			for _, v1 := range info.Violations {
+				log.Info(fmt.Sprintf("details: %+v", v1))
+			}
+		case *errdetails.ResourceInfo:
+			log.Infof("ResourceInfo: %s", info)
+		case *errdetails.BadRequest:
+			for _, v := range info.FieldViolations {
+				log.Info(fmt.Sprintf("BadRequest: %+v", v))
+			}
+		case *errdetails.ErrorInfo:
+			log.Infof("ErrorInfo: %s", info)
+		case *errdetails.DebugInfo:
+			log.WithField("stack", info.StackEntries).Errorf("DebugInfo: %s", info.Detail)
+		case *errdetails.RetryInfo:
+			log.Infof("Retry after %s", info.GetRetryDelay().AsDuration())
+		default:
+			log.Infof("Unexpected type: %s", info)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Command devcerts writes a local CA plus a server and a client certificate
+// signed by it, for trying out TLS and mTLS. The certificates are not meant
+// for production use.
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"io/fs"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	outDir      = flag.String("out", "certs", "directory to write the PEM files to")
+	hosts       = flag.String("hosts", "localhost,127.0.0.1", "comma separated DNS names and IPs of the server certificate")
+	clientName  = flag.String("client-name", "client", "common name of the client certificate, used as the principal subject")
+	clientRoles = flag.String("client-roles", "", "comma separated roles, stored as organizational units of the client certificate")
+	validFor    = flag.Duration("valid-for", 30*24*time.Hour, "lifetime of the server and client certificates")
+)
+
+func init() {
+	// Configure log package as json
+	log.SetFormatter(&log.JSONFormatter{})
+}
+
+func main() {
+	flag.Parse()
+
+	if err := os.MkdirAll(*outDir, 0o700); err != nil {
+		log.Fatalf("failed to create %s: %v", *outDir, err)
+	}
+
+	ca, caKey, err := loadOrCreateCA()
+	if err != nil {
+		log.Fatalf("failed to prepare CA: %v", err)
+	}
+
+	server := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: strings.Split(*hosts, ",")[0]},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range strings.Split(*hosts, ",") {
+		if ip := net.ParseIP(host); ip != nil {
+			server.IPAddresses = append(server.IPAddresses, ip)
+		} else if host != "" {
+			server.DNSNames = append(server.DNSNames, host)
+		}
+	}
+	if err := issue("server", server, ca, caKey); err != nil {
+		log.Fatalf("failed to issue server certificate: %v", err)
+	}
+
+	client := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: *clientName},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if *clientRoles != "" {
+		client.Subject.OrganizationalUnit = strings.Split(*clientRoles, ",")
+	}
+	if err := issue("client", client, ca, caKey); err != nil {
+		log.Fatalf("failed to issue client certificate: %v", err)
+	}
+
+	log.WithField("dir", *outDir).Info("Certificates written successfully!")
+}
+
+// loadOrCreateCA reuses ca.pem and ca-key.pem from the output directory, so
+// certificates issued by earlier runs stay valid.
+func loadOrCreateCA() (*x509.Certificate, crypto.Signer, error) {
+	pair, err := tls.LoadX509KeyPair(filepath.Join(*outDir, "ca.pem"), filepath.Join(*outDir, "ca-key.pem"))
+	if err == nil {
+		ca, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Info("Reusing existing CA")
+		return ca, pair.PrivateKey.(crypto.Signer), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "grpc-demo dev CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	key, der, err := sign(template, nil, nil, 10*365*24*time.Hour)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := write("ca", der, key); err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(der)
+	return ca, key, err
+}
+
+func issue(name string, template, ca *x509.Certificate, caKey crypto.Signer) error {
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	key, der, err := sign(template, ca, caKey, *validFor)
+	if err != nil {
+		return err
+	}
+	return write(name, der, key)
+}
+
+// sign creates a fresh key for template and signs it with parent, or
+// self-signs it when parent is nil.
+func sign(template, parent *x509.Certificate, parentKey crypto.Signer, lifetime time.Duration) (crypto.Signer, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(lifetime)
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	return key, der, err
+}
+
+// write stores <name>.pem and <name>-key.pem, replacing existing files
+// atomically so a server watching them never reads half a file.
+func write(name string, der []byte, key crypto.Signer) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(name+"-key.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return err
+	}
+	return writeFile(name+".pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+}
+
+func writeFile(name string, data []byte, perm os.FileMode) error {
+	path := filepath.Join(*outDir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
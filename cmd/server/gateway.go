@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sabuhigr/grpc-demo/internal/connectgw"
+	"github.com/sabuhigr/grpc-demo/internal/gateway"
+	"github.com/sabuhigr/grpc-demo/internal/openapi"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// gatewayServer serves the REST gateway, Connect and gRPC-Web over HTTP/1.1
+// and HTTP/2, with or without TLS. It calls an in-process gRPC server built
+// with the same options as the public one, minus TLS, so HTTP calls pass the
+// same interceptors. The interceptors see the address and client certificate
+// of the HTTP client as the peer.
+type gatewayServer struct {
+	http *http.Server
+	grpc *grpc.Server
+	conn *grpc.ClientConn
+}
+
+// serveGateway listens on addr, with TLS when tlsConfig is set. register
+// adds the services to the in-process gRPC server. Browsers of corsOrigins
+// may call it from other origins.
+func serveGateway(ctx context.Context, addr string, tlsConfig *tls.Config, corsOrigins []string, opts []grpc.ServerOption, register func(*grpc.Server)) (*gatewayServer, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// The peer of HTTP clients is set first, for the interceptors of opts.
+	peers := gateway.NewPeers()
+	inProcess := bufconn.Listen(1 << 20)
+	g := &gatewayServer{grpc: grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(peers.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(peers.StreamServerInterceptor()),
+	}, opts...)...)}
+	register(g.grpc)
+	go func() {
+		if err := g.grpc.Serve(inProcess); err != nil {
+			log.Errorf("gateway gRPC server failed: %v", err)
+		}
+	}()
+
+	g.conn, err = grpc.NewClient("passthrough:///gateway",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return inProcess.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithUnaryInterceptor(peers.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(peers.StreamClientInterceptor()),
+	)
+	if err != nil {
+		lis.Close()
+		return nil, err
+	}
+	handler, err := gateway.NewHandler(ctx, g.conn)
+	if err != nil {
+		lis.Close()
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", handler)
+	mux.Handle(connectgw.NewHandler(g.conn))
+	mux.Handle(connectgw.NewAPIKeyHandler(g.conn))
+	if err := openapi.Register(mux); err != nil {
+		lis.Close()
+		return nil, err
+	}
+
+	// Browsers speak HTTP/1.1 or HTTP/2 over TLS; other clients may use
+	// HTTP/2 without TLS, e.g. gRPC.
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	g.http = &http.Server{
+		Handler:           withCORS(corsOrigins, peers.Handler(mux)),
+		Protocols:         protocols,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	if tlsConfig != nil {
+		g.http.TLSConfig = withHTTP1(tlsConfig)
+	}
+	go func() {
+		log.WithField("addr", addr).Info("Serving REST gateway, Connect and gRPC-Web")
+		var err error
+		if tlsConfig != nil {
+			err = g.http.ServeTLS(lis, "", "")
+		} else {
+			err = g.http.Serve(lis)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("gateway listener failed: %v", err)
+		}
+	}()
+	return g, nil
+}
+
+// stop stops accepting requests and waits for the active ones until ctx is
+// done, then closes what is left.
+func (g *gatewayServer) stop(ctx context.Context) {
+	if err := g.http.Shutdown(ctx); err != nil {
+		log.Warn("Gateway requests did not finish in time, closing their connections")
+		g.http.Close()
+	}
+	g.conn.Close()
+	g.grpc.Stop()
+}
+
+// withHTTP1 offers HTTP/1.1 next to HTTP/2 on the server's TLS config, for
+// HTTP clients.
+func withHTTP1(cfg *tls.Config) *tls.Config {
+	cfg = cfg.Clone()
+	getConfig := cfg.GetConfigForClient
+	cfg.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		conn, err := getConfig(hello)
+		if err != nil {
+			return nil, err
+		}
+		conn.NextProtos = []string{"h2", "http/1.1"}
+		return conn, nil
+	}
+	return cfg
+}
+
+// withCORS lets browsers of origins call h from other origins, answering
+// their preflight requests. No origin is allowed when origins is empty.
+func withCORS(origins []string, h http.Handler) http.Handler {
+	if len(origins) == 0 {
+		return h
+	}
+	anyOrigin := slices.Contains(origins, "*")
+	allowHeaders := strings.Join(connectgw.Headers, ", ")
+	exposeHeaders := strings.Join(connectgw.ExposedHeaders, ", ")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !anyOrigin && !slices.Contains(origins, origin) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		header.Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE")
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+			header.Set("Access-Control-Max-Age", "7200")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		header.Set("Access-Control-Expose-Headers", exposeHeaders)
+		h.ServeHTTP(w, r)
+	})
+}
@@ -2,64 +2,399 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/google/uuid"
 	news1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/internal/apikey"
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"github.com/sabuhigr/grpc-demo/internal/authz"
+	"github.com/sabuhigr/grpc-demo/internal/certs"
+	"github.com/sabuhigr/grpc-demo/internal/config"
+	"github.com/sabuhigr/grpc-demo/internal/filestore"
 	ingrpc "github.com/sabuhigr/grpc-demo/internal/grpc"
+	"github.com/sabuhigr/grpc-demo/internal/idempotency"
+	"github.com/sabuhigr/grpc-demo/internal/logging"
 	"github.com/sabuhigr/grpc-demo/internal/memstore"
-	"github.com/sabuhigr/grpc-demo/types"
+	"github.com/sabuhigr/grpc-demo/internal/metrics"
+	"github.com/sabuhigr/grpc-demo/internal/ratelimit"
+	"github.com/sabuhigr/grpc-demo/internal/recovery"
+	"github.com/sabuhigr/grpc-demo/internal/tracing"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/status"
 )
 
-func init() {
-	// Configure log package as json
-	log.SetFormatter(&log.JSONFormatter{})
+// publicMethods need neither credentials nor a policy grant.
+var publicMethods = []string{"/grpc.health.v1.Health/"}
+
+// newAuthenticators builds the configured authenticators, in the order they
+// are tried.
+func newAuthenticators(cfg config.ServerAuth, clientCA string, store storer) ([]auth.Authenticator, error) {
+	var authenticators []auth.Authenticator
+
+	keys := auth.NewKeySet()
+	for _, spec := range cfg.JWTKeys {
+		kid, path, ok := strings.Cut(spec, "=")
+		if !ok {
+			kid, path = "", spec
+		}
+		key, err := auth.LoadVerificationKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := keys.Add(kid, key); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if cfg.JWKS != "" {
+		if err := keys.LoadJWKS(cfg.JWKS); err != nil {
+			return nil, err
+		}
+	}
+	if keys.Len() > 0 {
+		jwtAuth, err := auth.JWT(auth.JWTOptions{
+			Keys:     keys,
+			Issuer:   cfg.JWTIssuer,
+			Audience: cfg.JWTAudience,
+			Leeway:   cfg.JWTLeeway,
+		})
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, jwtAuth)
+	}
+
+	authenticators = append(authenticators, apikey.Authenticator(store))
+
+	if cfg.StaticToken != "" {
+		authenticators = append(authenticators, auth.StaticToken(cfg.StaticToken, auth.Principal{Subject: "static-token"}))
+	}
+
+	if clientCA != "" {
+		authenticators = append(authenticators, auth.ClientCertificate())
+	}
+
+	if len(authenticators) == 1 {
+		log.Warn("Only API keys are accepted, and there is no other way to authenticate to create one")
+	}
+	return authenticators, nil
+}
+
+// newTLSConfig returns the server TLS config and its certificates, or nils
+// for plaintext. Certificates are reloaded until ctx is done.
+func newTLSConfig(ctx context.Context, cfg config.ServerTLS) (*tls.Config, *certs.Reloader, error) {
+	if cfg.Cert == "" {
+		log.Warn("TLS is disabled, serving plaintext")
+		return nil, nil, nil
+	}
+
+	reloader, err := certs.NewReloader(certFiles(cfg))
+	if err != nil {
+		return nil, nil, err
+	}
+	go reloader.Watch(ctx, cfg.ReloadInterval)
+
+	clientAuth := tls.VerifyClientCertIfGiven
+	if cfg.RequireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	return certs.ServerConfig(reloader, clientAuth), reloader, nil
+}
+
+func certFiles(cfg config.ServerTLS) certs.Files {
+	return certs.Files{Cert: cfg.Cert, Key: cfg.Key, CA: cfg.ClientCA}
 }
 
-func unaryMetadataInterceptor(
-	ctx context.Context,
-	req interface{},
-	info *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (interface{}, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, status.Error(codes.InvalidArgument, "missing metadata")
+// newAuthorizer loads the policy file, if any. The owner of news is looked up
+// in store.
+func newAuthorizer(policyFile string, store ingrpc.NewsStorer) (*authz.Authorizer, error) {
+	if policyFile == "" {
+		log.Warn("No authorization policy configured, every authenticated call is allowed")
+		return nil, nil
 	}
+	owner := func(ctx context.Context, id string) (string, bool, error) {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return "", false, nil
+		}
+		news, err := store.Get(ctx, parsed, true)
+		if errors.Is(err, memstore.ErrNotFound) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return news.Author, true, nil
+	}
+	return authz.New(policyFile, owner)
+}
 
-	// Example: Check for token
-	if values := md["authorization"]; len(values) > 0 && values[0] == types.Static_token {
-		log.Info("Successfully authenticated")
-	} else {
-		return nil, status.Error(codes.Unauthenticated, "missing authorization token")
+// limiterOptions turns the configured limits into rate limiter options.
+func limiterOptions(cfg config.Limits) (ratelimit.Options, error) {
+	opts := ratelimit.Options{DailyQuotas: make(map[string]int64), ItemQuotas: make(map[string]string)}
+	if cfg.RateLimit != "" {
+		limit, err := ratelimit.ParseLimit(cfg.RateLimit)
+		if err != nil {
+			return ratelimit.Options{}, err
+		}
+		opts.Default = limit
 	}
+	methods, err := ratelimit.ParseMethodLimits(strings.Join(cfg.MethodRateLimits, ","))
+	if err != nil {
+		return ratelimit.Options{}, err
+	}
+	opts.Methods = methods
+	if cfg.CreateNewsDailyQuota > 0 {
+		opts.DailyQuotas[news1.NewsService_CreateNews_FullMethodName] = cfg.CreateNewsDailyQuota
+		// Bulk created news count against the same quota, one per item.
+		opts.ItemQuotas[news1.NewsService_BulkCreateNews_FullMethodName] = news1.NewsService_CreateNews_FullMethodName
+	}
+	return opts, nil
+}
 
-	// Continue with actual handler
-	return handler(ctx, req)
+// storer is what the configured backend persists: news and API keys.
+type storer interface {
+	ingrpc.NewsStorer
+	ingrpc.APIKeyStorer
+	Stats(ctx context.Context) (memstore.Stats, error)
+}
+
+// newStore opens the configured backend. The returned func releases it.
+func newStore(cfg config.Storage) (storer, func() error, error) {
+	switch cfg.Backend {
+	case "memory":
+		return memstore.New(), func() error { return nil }, nil
+	case "file":
+		policy, err := filestore.ParseSyncPolicy(cfg.Fsync)
+		if err != nil {
+			return nil, nil, err
+		}
+		store, err := filestore.Open(filestore.Options{
+			Dir:              cfg.DataDir,
+			Sync:             policy,
+			SyncInterval:     cfg.FsyncInterval,
+			SnapshotInterval: cfg.SnapshotInterval,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, store.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown store %q, want memory or file", cfg.Backend)
+	}
+}
+
+// serveMetrics serves /metrics on addr until the returned server is shut
+// down.
+func serveMetrics(addr string, m *metrics.Metrics) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		log.WithField("addr", addr).Info("Serving metrics")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("metrics listener failed: %v", err)
+		}
+	}()
+	return srv
+}
+
+// shutdown stops srv and the gateway, if any, gracefully. Health checks
+// report NOT_SERVING for the drain period so load balancers move traffic
+// away, then open streams are ended and in-flight calls get the timeout to
+// finish before the remaining connections are closed.
+func shutdown(cfg config.Shutdown, srv *grpc.Server, gw *gatewayServer, healthSrv *health.Server, newsSrv *ingrpc.Server) {
+	log.WithField("drain", cfg.Drain.String()).Info("Shutting down, draining")
+	healthSrv.Shutdown()
+	time.Sleep(cfg.Drain)
+
+	newsSrv.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	if gw != nil {
+		gw.stop(ctx)
+	}
+	select {
+	case <-stopped:
+		log.Info("Server stopped gracefully")
+	case <-ctx.Done():
+		log.Warn("Graceful stop timed out, closing remaining connections")
+		srv.Stop()
+	}
 }
 
 func main() {
-	lis, err := net.Listen("tcp", "127.0.0.1:8080")
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves until a signal or a listener failure stops the server. It
+// returns errors instead of exiting, so its deferred calls always flush and
+// close what was opened.
+func run(args []string) error {
+	cfg := config.DefaultServer()
+	if err := config.Load(os.Args[0], config.ServerEnvPrefix, args, cfg); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg.Logging.Apply()
+
+	store, closeStore, err := newStore(cfg.Storage)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to open store: %w", err)
 	}
+	defer func() {
+		if err := closeStore(); err != nil {
+			log.Errorf("failed to close store: %v", err)
+		}
+	}()
 
-	srv := grpc.NewServer(
-		grpc.UnaryInterceptor(unaryMetadataInterceptor),
-	)
-	news1.RegisterNewsServiceServer(srv, ingrpc.NewServer(memstore.New()))
+	lis, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.Options("news-server"))
+	if err != nil {
+		return fmt.Errorf("failed to configure tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Errorf("failed to flush traces: %v", err)
+		}
+	}()
+
+	tlsConfig, certReloader, err := newTLSConfig(ctx, cfg.TLS)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+
+	authenticators, err := newAuthenticators(cfg.Auth, cfg.TLS.ClientCA, store)
+	if err != nil {
+		return fmt.Errorf("failed to configure authentication: %w", err)
+	}
+	rpcMetrics := metrics.New()
+	if err := rpcMetrics.Register(metrics.NewStoreCollector(store.Stats)); err != nil {
+		return fmt.Errorf("failed to register store metrics: %w", err)
+	}
+	logs := logging.NewInterceptor(log.StandardLogger())
+	recoverer := recovery.New(cfg.DebugErrors).OnPanic(rpcMetrics.Panic)
+	authn := auth.NewInterceptor(authenticators...).Public(publicMethods...)
+	// Idempotent replays are answered before the limiter, so they use up
+	// neither rate limit tokens nor daily quota.
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		rpcMetrics.Unary(), logs.Unary(), recoverer.Unary(), authn.Unary(),
+		idempotency.New(cfg.Limits.IdempotencyRetention).UnaryServerInterceptor(news1.NewsService_CreateNews_FullMethodName),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{rpcMetrics.Stream(), logs.Stream(), recoverer.Stream(), authn.Stream()}
+
+	limits, err := limiterOptions(cfg.Limits)
+	if err != nil {
+		return fmt.Errorf("failed to configure rate limits: %w", err)
+	}
+	limiter := ratelimit.New(limits)
+	unaryInterceptors = append(unaryInterceptors, limiter.Unary())
+	streamInterceptors = append(streamInterceptors, limiter.Stream())
+
+	authorizer, err := newAuthorizer(cfg.Auth.PolicyFile, store)
+	if err != nil {
+		return fmt.Errorf("failed to load authorization policy: %w", err)
+	}
+	if authorizer != nil {
+		authorizer.Public(publicMethods...)
+		go authorizer.Watch(ctx, cfg.Auth.PolicyReloadInterval)
+		unaryInterceptors = append(unaryInterceptors, authorizer.Unary())
+		streamInterceptors = append(streamInterceptors, authorizer.Stream())
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+	newsSrv := ingrpc.NewServer(store)
+	apiKeySrv := ingrpc.NewAPIKeyServer(store)
+	register := func(s *grpc.Server) {
+		news1.RegisterNewsServiceServer(s, newsSrv)
+		news1.RegisterApiKeyServiceServer(s, apiKeySrv)
+	}
+
+	var creds []grpc.ServerOption
+	if tlsConfig != nil {
+		creds = append(creds, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	srv := grpc.NewServer(append(creds, opts...)...)
+	register(srv)
 	healthSrv := health.NewServer()
 	healthv1.RegisterHealthServer(srv, healthSrv)
 
-	log.Info("Starting gRPC server on port :8080")
+	var gw *gatewayServer
+	if cfg.HTTPListen != "" {
+		gw, err = serveGateway(ctx, cfg.HTTPListen, tlsConfig, cfg.CORSOrigins, opts, register)
+		if err != nil {
+			return fmt.Errorf("failed to start REST gateway: %w", err)
+		}
+	}
+
+	if cfg.MetricsListen != "" {
+		metricsSrv := serveMetrics(cfg.MetricsListen, rpcMetrics)
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+				log.Errorf("failed to stop metrics listener: %v", err)
+			}
+		}()
+	}
+
+	go (&reloader{
+		args:       args,
+		cfg:        cfg,
+		limiter:    limiter,
+		recoverer:  recoverer,
+		authorizer: authorizer,
+		certs:      certReloader,
+	}).watch(ctx)
+
+	signalCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	log.WithField("addr", cfg.Listen).Info("Starting gRPC server")
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(lis)
+	}()
 
-	if err := srv.Serve(lis); err != nil {
-		panic(err)
+	// Deferred calls flush the metrics listener, traces and the store, in
+	// that order.
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("gRPC server failed: %w", err)
+	case <-signalCtx.Done():
+		// A second signal kills the process right away.
+		stopSignals()
+		shutdown(cfg.Shutdown, srv, gw, healthSrv, newsSrv)
+		return nil
 	}
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sabuhigr/grpc-demo/internal/authz"
+	"github.com/sabuhigr/grpc-demo/internal/certs"
+	"github.com/sabuhigr/grpc-demo/internal/config"
+	"github.com/sabuhigr/grpc-demo/internal/ratelimit"
+	"github.com/sabuhigr/grpc-demo/internal/recovery"
+	log "github.com/sirupsen/logrus"
+)
+
+// reloader applies configuration changes to the running server, without
+// dropping connections or streams.
+type reloader struct {
+	args []string
+	cfg  *config.Server
+
+	limiter    *ratelimit.Limiter
+	recoverer  *recovery.Interceptor
+	authorizer *authz.Authorizer // nil without a policy
+	certs      *certs.Reloader   // nil without TLS
+}
+
+// watch reloads on every SIGHUP until ctx is done.
+func (r *reloader) watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+		if err := r.reload(); err != nil {
+			log.Errorf("failed to reload configuration, keeping the current one: %v", err)
+		}
+	}
+}
+
+// reload loads the configuration again from the same file, environment and
+// flags. Nothing is applied unless all of it, the policy file and the
+// certificates are valid. Settings that need a restart are reported and
+// left as they are.
+func (r *reloader) reload() error {
+	next := config.DefaultServer()
+	if err := config.Load(os.Args[0], config.ServerEnvPrefix, r.args, next); err != nil {
+		return err
+	}
+	limits, err := limiterOptions(next.Limits)
+	if err != nil {
+		return err
+	}
+	// The authorizer and the certificates keep the files they were started
+	// with; only their content is reloaded.
+	if r.authorizer != nil {
+		if _, err := authz.LoadPolicy(r.cfg.Auth.PolicyFile); err != nil {
+			return fmt.Errorf("authorization policy: %w", err)
+		}
+	}
+	if r.certs != nil {
+		if _, err := certs.NewReloader(certFiles(r.cfg.TLS)); err != nil {
+			return fmt.Errorf("certificates: %w", err)
+		}
+	}
+
+	applied, restart := config.Reload(r.cfg, next)
+	r.cfg.Logging.Apply()
+	r.limiter.SetOptions(limits)
+	r.recoverer.SetDebug(r.cfg.DebugErrors)
+	if r.authorizer != nil {
+		if err := r.authorizer.Reload(); err != nil {
+			log.Errorf("failed to reload policy, keeping the previous one: %v", err)
+		}
+	}
+	if r.certs != nil {
+		if err := r.certs.Reload(); err != nil {
+			log.Errorf("failed to reload certificates, keeping the previous ones: %v", err)
+		}
+	}
+
+	entry := log.WithField("applied", applied)
+	if len(restart) > 0 {
+		entry.WithField("restart_required", restart).Warn("Configuration reloaded, some changes need a restart")
+		return nil
+	}
+	entry.Info("Configuration reloaded")
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sabuhigr/grpc-demo/internal/config"
+	"github.com/sabuhigr/grpc-demo/internal/ratelimit"
+	"github.com/sabuhigr/grpc-demo/internal/recovery"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestReloadAppliesOnlyReloadableSettings(t *testing.T) {
+	level, formatter := log.GetLevel(), log.StandardLogger().Formatter
+	t.Cleanup(func() {
+		log.SetLevel(level)
+		log.SetFormatter(formatter)
+	})
+
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`
+listen: 127.0.0.1:7000
+limits:
+  rate_limit: "1:1"
+  idempotency_retention: 1h
+logging:
+  level: info
+`)
+	args := []string{"-config", path}
+	cfg := config.DefaultServer()
+	if err := config.Load("server", config.ServerEnvPrefix, args, cfg); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	limits, err := limiterOptions(cfg.Limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &reloader{
+		args:      args,
+		cfg:       cfg,
+		limiter:   ratelimit.New(limits),
+		recoverer: recovery.New(cfg.DebugErrors),
+	}
+
+	write(`
+listen: 127.0.0.1:7001
+debug_errors: true
+limits:
+  rate_limit: "5:10"
+  idempotency_retention: 2h
+logging:
+  level: debug
+`)
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	tests := []struct {
+		setting   string
+		got, want any
+	}{
+		{"logging.level", cfg.Logging.Level, "debug"},
+		{"applied log level", log.GetLevel(), log.DebugLevel},
+		{"debug_errors", cfg.DebugErrors, true},
+		{"limits.rate_limit", cfg.Limits.RateLimit, "5:10"},
+		{"listen (needs a restart)", cfg.Listen, "127.0.0.1:7000"},
+		{"limits.idempotency_retention (needs a restart)", cfg.Limits.IdempotencyRetention, time.Hour},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.setting, tt.got, tt.want)
+		}
+	}
+
+	// A broken file changes nothing.
+	write(`
+limits:
+  rate_limit: "fast"
+logging:
+  level: warn
+`)
+	if err := r.reload(); err == nil {
+		t.Fatal("reload of an invalid config succeeded")
+	}
+	if cfg.Logging.Level != "debug" || cfg.Limits.RateLimit != "5:10" {
+		t.Errorf("failed reload changed the config: log level %q, rate limit %q", cfg.Logging.Level, cfg.Limits.RateLimit)
+	}
+}
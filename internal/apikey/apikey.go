@@ -0,0 +1,107 @@
+// Package apikey issues API key secrets and authenticates calls that carry
+// them. Secrets look like nk_<key id>_<random>; only their SHA-256 hash is
+// stored, which is enough for 256 bits of randomness.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"github.com/sabuhigr/grpc-demo/internal/logging"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+)
+
+// Prefix marks API key secrets, so they can be told apart from JWTs.
+const Prefix = "nk_"
+
+// Store is the part of the store the authenticator needs.
+type Store interface {
+	GetAPIKey(ctx context.Context, id uuid.UUID) (*memstore.APIKey, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// NewSecret returns a fresh secret for the key id and its hash.
+func NewSecret(id uuid.UUID) (string, []byte, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", nil, err
+	}
+	secret := Prefix + hex.EncodeToString(id[:]) + "_" + base64.RawURLEncoding.EncodeToString(random)
+	return secret, Hash(secret), nil
+}
+
+// Hash returns the stored form of a secret.
+func Hash(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// parse extracts the key id from a secret.
+func parse(secret string) (uuid.UUID, bool) {
+	rest, ok := strings.CutPrefix(secret, Prefix)
+	if !ok || len(rest) < 33 || rest[32] != '_' {
+		return uuid.UUID{}, false
+	}
+	raw, err := hex.DecodeString(rest[:32])
+	if err != nil {
+		return uuid.UUID{}, false
+	}
+	id, err := uuid.FromBytes(raw)
+	return id, err == nil
+}
+
+// Authenticator accepts bearer tokens that are live API keys of store and
+// authenticates them as the key owner, with the key scopes as roles. Every
+// use is recorded, at a granularity of a minute.
+func Authenticator(store Store) auth.Authenticator {
+	return auth.AuthenticatorFunc(func(ctx context.Context) (*auth.Principal, error) {
+		secret, ok := auth.BearerToken(ctx)
+		if !ok || !strings.HasPrefix(secret, Prefix) {
+			return nil, auth.ErrNoCredentials
+		}
+		id, ok := parse(secret)
+		if !ok {
+			return nil, errors.New("malformed api key")
+		}
+
+		key, err := store.GetAPIKey(ctx, id)
+		if errors.Is(err, memstore.ErrNotFound) {
+			return nil, errors.New("unknown api key")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		hash := Hash(secret)
+		current := subtle.ConstantTimeCompare(hash, key.Hash) == 1
+		previous := len(key.PreviousHash) > 0 && now.Before(key.PreviousExpiresAt) &&
+			subtle.ConstantTimeCompare(hash, key.PreviousHash) == 1
+		switch {
+		case !current && !previous:
+			return nil, errors.New("unknown api key")
+		case !key.RevokedAt.IsZero():
+			return nil, errors.New("api key is revoked")
+		case !key.ExpiresAt.IsZero() && !now.Before(key.ExpiresAt):
+			return nil, errors.New("api key is expired")
+		}
+
+		if err := store.TouchAPIKey(ctx, id, now); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("api_key", id).Warn("Failed to record api key use")
+		}
+		return &auth.Principal{
+			Subject: key.Owner,
+			Roles:   key.Scopes,
+			Method:  auth.MethodAPIKey,
+		}, nil
+	})
+}
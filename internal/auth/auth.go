@@ -0,0 +1,84 @@
+// Package auth authenticates unary and streaming calls with pluggable
+// authenticators and hands the resulting principal to handlers through the
+// request context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrNoCredentials is returned by an authenticator when the call carries no
+// credentials it understands, so the next authenticator gets a chance.
+var ErrNoCredentials = errors.New("no credentials")
+
+// AdminRole is the role that may manage the API keys of every owner, grant
+// any scope and read deleted news.
+const AdminRole = "admin"
+
+// MethodAPIKey is the Method of principals authenticated with an API key.
+// Their roles are the key's scopes and nothing else: roles granted to the
+// key's owner do not extend to the key.
+const MethodAPIKey = "api-key"
+
+// Principal is the authenticated caller.
+type Principal struct {
+	// Subject identifies the caller, e.g. a user name or key id.
+	Subject string
+	// Roles are the roles granted to the caller.
+	Roles []string
+	// Method names the authenticator that accepted the call.
+	Method string
+	// Claims holds the verified token claims, if the method uses tokens.
+	Claims map[string]any
+}
+
+// HasRole reports whether the principal was granted role.
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// Authenticator establishes who is calling from the incoming context.
+// It returns ErrNoCredentials when the call carries nothing it understands
+// and any other error to reject the call.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Principal, error)
+}
+
+// AuthenticatorFunc adapts a function to Authenticator.
+type AuthenticatorFunc func(ctx context.Context) (*Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context) (*Principal, error) {
+	return f(ctx)
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of an authenticated call.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// BearerToken returns the token of the authorization metadata. The "Bearer "
+// scheme prefix is optional.
+func BearerToken(ctx context.Context) (string, bool) {
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+	token := values[0]
+	if len(token) > len("bearer ") && strings.EqualFold(token[:len("bearer ")], "bearer ") {
+		token = token[len("bearer "):]
+	}
+	return token, true
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sabuhigr/grpc-demo/internal/logging"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor authenticates every call with the first authenticator that
+// recognizes its credentials.
+type Interceptor struct {
+	authenticators []Authenticator
+	public         []string
+}
+
+// NewInterceptor tries the authenticators in order.
+func NewInterceptor(authenticators ...Authenticator) *Interceptor {
+	return &Interceptor{authenticators: authenticators}
+}
+
+// Public lets calls to the given full method names, or to every method of a
+// service when given a "/package.Service/" prefix, through without
+// credentials.
+func (i *Interceptor) Public(methods ...string) *Interceptor {
+	i.public = append(i.public, methods...)
+	return i
+}
+
+// Unary returns the interceptor for unary calls.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := i.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the interceptor for streaming calls.
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if i.isPublic(method) {
+		return ctx, nil
+	}
+
+	for _, authenticator := range i.authenticators {
+		principal, err := authenticator.Authenticate(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			logging.FromContext(ctx).Debugf("Authentication failed: %v", err)
+			if _, ok := status.FromError(err); ok {
+				return nil, err
+			}
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		ctx = logging.WithFields(ctx, log.Fields{"principal": principal.Subject, "auth_method": principal.Method})
+		return NewContext(ctx, principal), nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "missing or unrecognized credentials")
+}
+
+func (i *Interceptor) isPublic(method string) bool {
+	for _, public := range i.public {
+		if method == public || (strings.HasSuffix(public, "/") && strings.HasPrefix(method, public)) {
+			return true
+		}
+	}
+	return false
+}
+
+// serverStream overrides the context of a stream with the authenticated one.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RolesClaim is the JWT claim listing the roles of the subject.
+const RolesClaim = "roles"
+
+// JWTOptions configures JWT validation.
+type JWTOptions struct {
+	Keys *KeySet
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew on exp and nbf.
+	Leeway time.Duration
+}
+
+// JWT accepts HS256, RS256 and EdDSA signed bearer tokens. Tokens must carry
+// exp; nbf is honoured when present. The claims are exposed on the
+// principal. Bearer values that are not shaped like a JWT are left to the
+// next authenticator.
+func JWT(opts JWTOptions) (Authenticator, error) {
+	if opts.Keys == nil || opts.Keys.Len() == 0 {
+		return nil, errors.New("jwt: no verification keys")
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	parser := jwt.NewParser(parserOpts...)
+
+	return AuthenticatorFunc(func(ctx context.Context) (*Principal, error) {
+		raw, ok := BearerToken(ctx)
+		if !ok || strings.Count(raw, ".") != 2 {
+			return nil, ErrNoCredentials
+		}
+
+		claims := jwt.MapClaims{}
+		if _, err := parser.ParseWithClaims(raw, claims, opts.Keys.keyFunc); err != nil {
+			return nil, fmt.Errorf("invalid token: %w", err)
+		}
+		subject, err := claims.GetSubject()
+		if err != nil || subject == "" {
+			return nil, errors.New("invalid token: missing sub claim")
+		}
+
+		return &Principal{
+			Subject: subject,
+			Roles:   stringsClaim(claims[RolesClaim]),
+			Method:  "jwt",
+			Claims:  claims,
+		}, nil
+	}), nil
+}
+
+// keyFunc picks the verification key by the token's kid and makes sure the
+// signing algorithm fits the key, so an RSA public key can never be used as
+// an HMAC secret.
+func (ks *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	var want string
+	switch key.(type) {
+	case []byte:
+		want = "HS256"
+	case *rsa.PublicKey:
+		want = "RS256"
+	case ed25519.PublicKey:
+		want = "EdDSA"
+	}
+	if token.Method.Alg() != want {
+		return nil, fmt.Errorf("algorithm %s does not match key %q", token.Method.Alg(), kid)
+	}
+	return key, nil
+}
+
+// MintJWT signs claims with a key from LoadSigningKey.
+func MintJWT(key any, kid string, claims jwt.Claims) (string, error) {
+	var method jwt.SigningMethod
+	switch key.(type) {
+	case []byte:
+		method = jwt.SigningMethodHS256
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return "", fmt.Errorf("unsupported signing key %T", key)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString(key)
+}
+
+// stringsClaim reads a claim holding either a list of strings or a single
+// space separated string.
+func stringsClaim(claim any) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
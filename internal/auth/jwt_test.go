@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/metadata"
+)
+
+var hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// testKeys are the signing keys of the tests, with their verification keys
+// registered in a KeySet under the kids "hmac", "rsa" and "ed".
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ed  ed25519.PrivateKey
+	set *KeySet
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := NewKeySet()
+	for kid, key := range map[string]any{"hmac": hmacSecret, "rsa": &rsaKey.PublicKey, "ed": edKey.Public()} {
+		if err := set.Add(kid, key); err != nil {
+			t.Fatalf("Add(%s): %v", kid, err)
+		}
+	}
+	return &testKeys{rsa: rsaKey, ed: edKey, set: set}
+}
+
+func withBearer(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+// claims returns valid claims for sub, with overrides applied.
+func claims(sub string, overrides jwt.MapClaims) jwt.MapClaims {
+	now := time.Now()
+	c := jwt.MapClaims{
+		"sub": sub,
+		"iss": "news-issuer",
+		"aud": "news-api",
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(c, k)
+			continue
+		}
+		c[k] = v
+	}
+	return c
+}
+
+func mint(t *testing.T, key any, kid string, c jwt.MapClaims) string {
+	t.Helper()
+	token, err := MintJWT(key, kid, c)
+	if err != nil {
+		t.Fatalf("MintJWT: %v", err)
+	}
+	return token
+}
+
+// signed signs c with an arbitrary method, for tokens MintJWT refuses to make.
+func signed(t *testing.T, method jwt.SigningMethod, key any, kid string, c jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, c)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return raw
+}
+
+func TestJWT(t *testing.T) {
+	keys := newTestKeys(t)
+	authn, err := JWT(JWTOptions{Keys: keys.set, Issuer: "news-issuer", Audience: "news-api", Leeway: time.Second})
+	if err != nil {
+		t.Fatalf("JWT: %v", err)
+	}
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hour := time.Hour
+
+	tests := []struct {
+		name      string
+		token     string
+		wantErr   error
+		wantRoles []string
+	}{
+		{"hmac", mint(t, hmacSecret, "hmac", claims("alice", jwt.MapClaims{"roles": []string{"editor", "reader"}})), nil, []string{"editor", "reader"}},
+		{"rsa", mint(t, keys.rsa, "rsa", claims("alice", jwt.MapClaims{"roles": "editor reader"})), nil, []string{"editor", "reader"}},
+		{"ed25519", mint(t, keys.ed, "ed", claims("alice", nil)), nil, nil},
+		{"not before within leeway", mint(t, hmacSecret, "hmac", claims("alice", jwt.MapClaims{"nbf": time.Now().Add(500 * time.Millisecond).Unix()})), nil, nil},
+
+		{"hs256 signed with the rsa public key", signed(t, jwt.SigningMethodHS256, rsaPublic, "rsa", claims("alice", nil)), jwt.ErrTokenUnverifiable, nil},
+		{"rs256 token under an hmac kid", signed(t, jwt.SigningMethodRS256, keys.rsa, "hmac", claims("alice", nil)), jwt.ErrTokenUnverifiable, nil},
+		{"eddsa token under an rsa kid", signed(t, jwt.SigningMethodEdDSA, keys.ed, "rsa", claims("alice", nil)), jwt.ErrTokenUnverifiable, nil},
+		{"alg none", signed(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "hmac", claims("alice", nil)), jwt.ErrTokenSignatureInvalid, nil},
+		{"hs512", signed(t, jwt.SigningMethodHS512, hmacSecret, "hmac", claims("alice", nil)), jwt.ErrTokenSignatureInvalid, nil},
+		{"wrong secret", mint(t, []byte("another secret, also 32 bytes long"), "hmac", claims("alice", nil)), jwt.ErrTokenSignatureInvalid, nil},
+
+		{"expired", mint(t, hmacSecret, "hmac", claims("alice", jwt.MapClaims{"exp": time.Now().Add(-hour).Unix()})), jwt.ErrTokenExpired, nil},
+		{"no exp", mint(t, hmacSecret, "hmac", claims("alice", jwt.MapClaims{"exp": nil})), jwt.ErrTokenRequiredClaimMissing, nil},
+		{"not yet valid", mint(t, hmacSecret, "hmac", claims("alice", jwt.MapClaims{"nbf": time.Now().Add(hour).Unix()})), jwt.ErrTokenNotValidYet, nil},
+		{"wrong issuer", mint(t, hmacSecret, "hmac", claims("alice", jwt.MapClaims{"iss": "someone-else"})), jwt.ErrTokenInvalidIssuer, nil},
+		{"wrong audience", mint(t, hmacSecret, "hmac", claims("alice", jwt.MapClaims{"aud": "other-api"})), jwt.ErrTokenInvalidAudience, nil},
+		{"unknown kid", mint(t, hmacSecret, "retired", claims("alice", nil)), jwt.ErrTokenUnverifiable, nil},
+		{"no kid with several keys", mint(t, hmacSecret, "", claims("alice", nil)), jwt.ErrTokenUnverifiable, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authn.Authenticate(withBearer(tt.token))
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("token accepted for %q", principal.Subject)
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if principal.Subject != "alice" || principal.Method != "jwt" || !slices.Equal(principal.Roles, tt.wantRoles) {
+				t.Errorf("principal %+v, want alice by jwt with roles %v", principal, tt.wantRoles)
+			}
+			if principal.Claims["iss"] != "news-issuer" {
+				t.Errorf("claims %v miss iss", principal.Claims)
+			}
+		})
+	}
+
+	if _, err := authn.Authenticate(withBearer(mint(t, hmacSecret, "hmac", claims("", nil)))); err == nil {
+		t.Error("token without sub accepted")
+	}
+}
+
+func TestJWTLeavesOtherCredentials(t *testing.T) {
+	authn, err := JWT(JWTOptions{Keys: newTestKeys(t).set})
+	if err != nil {
+		t.Fatalf("JWT: %v", err)
+	}
+	for _, ctx := range []context.Context{context.Background(), withBearer("nk_123_secret")} {
+		if _, err := authn.Authenticate(ctx); !errors.Is(err, ErrNoCredentials) {
+			t.Errorf("Authenticate: %v, want ErrNoCredentials", err)
+		}
+	}
+}
+
+func TestJWTSingleKeyWithoutKid(t *testing.T) {
+	set := NewKeySet()
+	if err := set.Add("", hmacSecret); err != nil {
+		t.Fatal(err)
+	}
+	authn, err := JWT(JWTOptions{Keys: set})
+	if err != nil {
+		t.Fatalf("JWT: %v", err)
+	}
+	if _, err := authn.Authenticate(withBearer(mint(t, hmacSecret, "", claims("alice", nil)))); err != nil {
+		t.Errorf("token without kid: %v", err)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+	b64 := base64.RawURLEncoding
+	jwks := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64.EncodeToString(keys.rsa.N.Bytes()), "e": "AQAB"},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64.EncodeToString(keys.ed.Public().(ed25519.PublicKey))},
+		{"kty": "oct", "kid": "hmac", "k": b64.EncodeToString(hmacSecret)},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}}
+	raw, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	set := NewKeySet()
+	if err := set.LoadJWKS(path); err != nil {
+		t.Fatalf("LoadJWKS: %v", err)
+	}
+	if set.Len() != 3 {
+		t.Fatalf("loaded %d keys, want 3 without the encryption key", set.Len())
+	}
+	authn, err := JWT(JWTOptions{Keys: set})
+	if err != nil {
+		t.Fatalf("JWT: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"rsa", mint(t, keys.rsa, "rsa", claims("alice", nil)), true},
+		{"ed25519", mint(t, keys.ed, "ed", claims("alice", nil)), true},
+		{"hmac", mint(t, hmacSecret, "hmac", claims("alice", nil)), true},
+		{"encryption key", mint(t, keys.rsa, "enc", claims("alice", nil)), false},
+		{"unknown kid", mint(t, keys.rsa, "rotated-out", claims("alice", nil)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authn.Authenticate(withBearer(tt.token))
+			if tt.ok && err != nil {
+				t.Errorf("Authenticate: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, jwt.ErrTokenUnverifiable) {
+				t.Errorf("Authenticate: %v, want an unverifiable token", err)
+			}
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	keys := newTestKeys(t)
+	dir := t.TempDir()
+	write := func(name string, raw []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, raw, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(keys.ed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkix, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPrivate := write("rsa.pem", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(keys.rsa)}))
+	rsaPublic := write("rsa.pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+	edPrivate := write("ed.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	secret := write("secret", append(hmacSecret, '\n'))
+	short := write("short", []byte("too short"))
+	unknownPEM := write("ec.pem", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte{1}}))
+
+	verify := []struct {
+		path string
+		want any
+	}{
+		{rsaPrivate, &keys.rsa.PublicKey},
+		{rsaPublic, &keys.rsa.PublicKey},
+		{edPrivate, keys.ed.Public()},
+		{secret, hmacSecret},
+	}
+	for _, tt := range verify {
+		key, err := LoadVerificationKey(tt.path)
+		if err != nil {
+			t.Errorf("LoadVerificationKey(%s): %v", filepath.Base(tt.path), err)
+			continue
+		}
+		var equal bool
+		switch k := key.(type) {
+		case []byte:
+			equal = bytes.Equal(k, tt.want.([]byte))
+		case interface{ Equal(crypto.PublicKey) bool }:
+			equal = k.Equal(tt.want)
+		}
+		if !equal {
+			t.Errorf("LoadVerificationKey(%s) = %T, want %T", filepath.Base(tt.path), key, tt.want)
+		}
+	}
+	for _, path := range []string{short, unknownPEM} {
+		if _, err := LoadVerificationKey(path); err == nil {
+			t.Errorf("LoadVerificationKey(%s) succeeded", filepath.Base(path))
+		}
+	}
+	if _, err := LoadSigningKey(rsaPublic); err == nil {
+		t.Error("LoadSigningKey accepted a public key")
+	}
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// KeySet holds the keys that verify JWT signatures, by key id. HMAC
+// secrets are []byte, public keys are *rsa.PublicKey or ed25519.PublicKey.
+type KeySet struct {
+	keys map[string]any
+}
+
+// NewKeySet returns an empty key set.
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]any)}
+}
+
+// Add registers key under kid. Use an empty kid for tokens without one.
+func (ks *KeySet) Add(kid string, key any) error {
+	switch key.(type) {
+	case []byte, *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	if _, ok := ks.keys[kid]; ok {
+		return fmt.Errorf("duplicate key id %q", kid)
+	}
+	ks.keys[kid] = key
+	return nil
+}
+
+// Len returns the number of keys.
+func (ks *KeySet) Len() int {
+	return len(ks.keys)
+}
+
+// lookup finds the key for kid. A token without kid may use the only key of
+// the set.
+func (ks *KeySet) lookup(kid string) (any, bool) {
+	if key, ok := ks.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// LoadVerificationKey reads a key file. PEM public keys, certificates and
+// private keys yield the public key; any other content is taken as an HMAC
+// secret, with surrounding whitespace trimmed.
+func LoadVerificationKey(path string) (any, error) {
+	key, err := loadKey(path)
+	if err != nil {
+		return nil, err
+	}
+	if signer, ok := key.(crypto.Signer); ok {
+		return signer.Public(), nil
+	}
+	return key, nil
+}
+
+// LoadSigningKey reads a PEM private key or an HMAC secret file, for
+// minting tokens.
+func LoadSigningKey(path string) (any, error) {
+	key, err := loadKey(path)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case []byte, *rsa.PrivateKey, ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%s: not a private key or secret", path)
+	}
+}
+
+func loadKey(path string) (any, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		secret := bytes.TrimSpace(raw)
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("%s: HMAC secret must be at least 32 bytes", path)
+		}
+		return secret, nil
+	}
+
+	var key any
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey, ed25519.PublicKey, ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T, want RSA or Ed25519", path, key)
+	}
+}
+
+// jwk is the subset of RFC 7517 needed for RSA, Ed25519 and HMAC keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	K   string `json:"k"`
+}
+
+// LoadJWKS reads a JSON Web Key Set file into ks. Keys meant for
+// encryption are skipped.
+func (ks *KeySet) LoadJWKS(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.key()
+		if err != nil {
+			return fmt.Errorf("%s: key %d: %w", path, i, err)
+		}
+		if err := ks.Add(k.Kid, key); err != nil {
+			return fmt.Errorf("%s: key %d: %w", path, i, err)
+		}
+	}
+	return nil
+}
+
+func (k jwk) key() (any, error) {
+	b64 := base64.RawURLEncoding
+	switch k.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := b64.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		secret, err := b64.DecodeString(k.K)
+		if err != nil {
+			return nil, fmt.Errorf("secret: %w", err)
+		}
+		return secret, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientCertificate authenticates callers by their verified TLS client
+// certificate. The subject is the first URI SAN, DNS SAN or email SAN, in
+// that order, falling back to the common name. The organizational units of
+// the certificate subject become the roles.
+func ClientCertificate() Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context) (*Principal, error) {
+		p, ok := peer.FromContext(ctx)
+		if !ok {
+			return nil, ErrNoCredentials
+		}
+		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+		if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+			return nil, ErrNoCredentials
+		}
+
+		cert := tlsInfo.State.VerifiedChains[0][0]
+		subject := certificateSubject(cert)
+		if subject == "" {
+			return nil, errors.New("client certificate carries no identity")
+		}
+		return &Principal{
+			Subject: subject,
+			Roles:   cert.Subject.OrganizationalUnit,
+			Method:  "mtls",
+		}, nil
+	})
+}
+
+func certificateSubject(cert *x509.Certificate) string {
+	switch {
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	default:
+		return cert.Subject.CommonName
+	}
+}
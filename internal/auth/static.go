@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+)
+
+// StaticToken accepts calls whose authorization metadata carries token and
+// authenticates them as principal. Any other token is left to the next
+// authenticator.
+func StaticToken(token string, principal Principal) Authenticator {
+	principal.Method = "static-token"
+	return AuthenticatorFunc(func(ctx context.Context) (*Principal, error) {
+		got, ok := BearerToken(ctx)
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return nil, ErrNoCredentials
+		}
+		p := principal
+		return &p, nil
+	})
+}
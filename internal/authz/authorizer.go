@@ -0,0 +1,246 @@
+package authz
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const errorDomain = "news.v1"
+
+// OwnerFunc returns the author of the news with the id, deleted or not.
+// found is false when there is no such news.
+type OwnerFunc func(ctx context.Context, id string) (author string, found bool, err error)
+
+// Authorizer enforces the policy of a file on every call. The file is
+// re-read when it changes, and a broken edit keeps the last good policy.
+type Authorizer struct {
+	path   string
+	owner  OwnerFunc
+	public []string
+
+	policy atomic.Pointer[Policy]
+
+	mu      sync.Mutex
+	modTime time.Time
+}
+
+// New loads the policy at path. owner resolves the author of news for
+// allow_own grants.
+func New(path string, owner OwnerFunc) (*Authorizer, error) {
+	a := &Authorizer{path: path, owner: owner}
+	if err := a.Reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Public lets calls to the given full method names, or to every method of a
+// service when given a "/package.Service/" prefix, through unchecked.
+func (a *Authorizer) Public(methods ...string) *Authorizer {
+	a.public = append(a.public, methods...)
+	return a
+}
+
+// Reload re-reads the policy file. On error the current policy stays.
+func (a *Authorizer) Reload() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return err
+	}
+	policy, err := LoadPolicy(a.path)
+	if err != nil {
+		return err
+	}
+	a.policy.Store(policy)
+	a.modTime = info.ModTime()
+	return nil
+}
+
+// Watch reloads the policy whenever the file's modification time changes,
+// checking every interval until ctx is done.
+func (a *Authorizer) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(a.path)
+		if err != nil {
+			log.Errorf("failed to stat policy %s: %v", a.path, err)
+			continue
+		}
+		a.mu.Lock()
+		changed := !info.ModTime().Equal(a.modTime)
+		// Remember the attempt, so a broken file is reported once per edit.
+		a.modTime = info.ModTime()
+		a.mu.Unlock()
+		if !changed {
+			continue
+		}
+
+		if err := a.Reload(); err != nil {
+			log.Errorf("failed to reload policy, keeping the previous one: %v", err)
+			continue
+		}
+		log.WithField("path", a.path).Info("Authorization policy reloaded")
+	}
+}
+
+// Unary returns the interceptor for unary calls. It must run after
+// authentication.
+func (a *Authorizer) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if a.isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, granted, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		if granted == allowOwn {
+			if err := a.checkOwner(ctx, info.FullMethod, req); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the interceptor for streaming calls. With an allow_own
+// grant, every received message is checked.
+func (a *Authorizer) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a.isPublic(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, granted, err := a.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		ss = &serverStream{ServerStream: ss, ctx: ctx}
+		if granted == allowOwn {
+			ss = &ownedStream{ServerStream: ss, authorizer: a, method: info.FullMethod}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authorize decides whether the principal may call method. The returned
+// context carries the principal with every role the policy grants it, so
+// handlers can check roles such as auth.AdminRole.
+func (a *Authorizer) authorize(ctx context.Context, method string) (context.Context, decision, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return ctx, deny, status.Error(codes.Unauthenticated, "missing or unrecognized credentials")
+	}
+	policy := a.policy.Load()
+	granted := policy.decide(principal, method)
+	if granted == deny {
+		return ctx, deny, permissionDenied("METHOD_NOT_ALLOWED", "not allowed to call "+method, principal, method, nil)
+	}
+	withRoles := *principal
+	withRoles.Roles = policy.roles(principal)
+	return auth.NewContext(ctx, &withRoles), granted, nil
+}
+
+// checkOwner makes sure the request only touches news authored by the
+// principal: both the stored news it targets and the author it sets.
+func (a *Authorizer) checkOwner(ctx context.Context, method string, req any) error {
+	principal, _ := auth.FromContext(ctx)
+
+	if bulk, ok := req.(*newsv1.BulkCreateNewsRequest); ok {
+		req = bulk.GetNews()
+	}
+	if r, ok := req.(interface{ GetAuthor() string }); ok && r.GetAuthor() != "" && r.GetAuthor() != principal.Subject {
+		return permissionDenied("NOT_OWNER", "news must be authored by "+principal.Subject, principal, method,
+			map[string]string{"author": r.GetAuthor()})
+	}
+
+	r, ok := req.(interface{ GetId() string })
+	if !ok || r.GetId() == "" {
+		return nil
+	}
+	author, found, err := a.owner(ctx, r.GetId())
+	if err != nil {
+		return err
+	}
+	// An unknown id is left to the handler, which reports it as not found.
+	if found && author != principal.Subject {
+		return permissionDenied("NOT_OWNER", "news "+r.GetId()+" is not authored by "+principal.Subject, principal, method,
+			map[string]string{"id": r.GetId()})
+	}
+	return nil
+}
+
+func (a *Authorizer) isPublic(method string) bool {
+	for _, public := range a.public {
+		if method == public || (strings.HasSuffix(public, "/") && strings.HasPrefix(method, public)) {
+			return true
+		}
+	}
+	return false
+}
+
+func permissionDenied(reason, message string, principal *auth.Principal, method string, extra map[string]string) error {
+	metadata := map[string]string{
+		"method":  method,
+		"subject": principal.Subject,
+	}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+
+	st := status.New(codes.PermissionDenied, message)
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   errorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// serverStream overrides the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// ownedStream checks ownership of every message the client sends.
+type ownedStream struct {
+	grpc.ServerStream
+	authorizer *Authorizer
+	method     string
+}
+
+func (s *ownedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.authorizer.checkOwner(s.Context(), s.method, m)
+}
@@ -0,0 +1,232 @@
+package authz
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const testPolicy = `
+roles:
+  reader:
+    allow: ["/news.v1.NewsService/GetNews", "/news.v1.NewsService/ListNews", "/news.v1.NewsService/GetAll"]
+  editor:
+    allow_own: ["/news.v1.NewsService/UpdateNews", "/news.v1.NewsService/DeleteNews", "/news.v1.NewsService/BulkCreateNews"]
+  admin:
+    allow: ["*"]
+subjects:
+  alice: [admin]
+default_roles: [reader]
+`
+
+const (
+	createKeyMethod = "/news.v1.ApiKeyService/CreateApiKey"
+	updateMethod    = "/news.v1.NewsService/UpdateNews"
+	deleteMethod    = "/news.v1.NewsService/DeleteNews"
+	bulkMethod      = "/news.v1.NewsService/BulkCreateNews"
+	getAllMethod    = "/news.v1.NewsService/GetAll"
+)
+
+// authors of the news the ownership tests touch.
+var authors = map[string]string{"news-of-bob": "bob", "news-of-carol": "carol"}
+
+// newAuthorizer returns an Authorizer enforcing testPolicy, with news
+// authors taken from authors.
+func newAuthorizer(t *testing.T, authors map[string]string) *Authorizer {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(path, func(ctx context.Context, id string) (string, bool, error) {
+		author, ok := authors[id]
+		return author, ok, nil
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return a
+}
+
+// callUnary runs req through the unary interceptor and returns the
+// principal the handler saw, or the error.
+func callUnary(a *Authorizer, principal *auth.Principal, method string, req any) (*auth.Principal, error) {
+	var seen *auth.Principal
+	_, err := a.Unary()(auth.NewContext(context.Background(), principal), req, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req any) (any, error) {
+			seen, _ = auth.FromContext(ctx)
+			return nil, nil
+		})
+	return seen, err
+}
+
+func TestAPIKeyRolesAreItsScopes(t *testing.T) {
+	a := newAuthorizer(t, nil)
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		wantCode  codes.Code
+		wantRoles []string
+	}{
+		{"admin by subject", &auth.Principal{Subject: "alice", Method: "jwt"}, codes.OK, []string{"admin", "reader"}},
+		{"reader key of an admin", &auth.Principal{Subject: "alice", Roles: []string{"reader"}, Method: auth.MethodAPIKey}, codes.PermissionDenied, nil},
+		{"key without scopes", &auth.Principal{Subject: "alice", Method: auth.MethodAPIKey}, codes.PermissionDenied, nil},
+		{"admin key", &auth.Principal{Subject: "bob", Roles: []string{"admin"}, Method: auth.MethodAPIKey}, codes.OK, []string{"admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen, err := callUnary(a, tt.principal, createKeyMethod, nil)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code %v, want %v (%v)", got, tt.wantCode, err)
+			}
+			if err == nil && !slices.Equal(seen.Roles, tt.wantRoles) {
+				t.Errorf("handler saw roles %v, want %v", seen.Roles, tt.wantRoles)
+			}
+		})
+	}
+}
+
+// deniedReason returns the ErrorInfo reason of a PermissionDenied error.
+func deniedReason(t *testing.T, err error) string {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.PermissionDenied {
+		t.Fatalf("code %v, want PermissionDenied (%v)", st.Code(), err)
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	t.Fatalf("no ErrorInfo in %v", st.Details())
+	return ""
+}
+
+func TestCheckOwner(t *testing.T) {
+	a := newAuthorizer(t, authors)
+	bob := &auth.Principal{Subject: "bob", Roles: []string{"editor"}, Method: "jwt"}
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		method     string
+		req        any
+		wantReason string
+	}{
+		{"owner updates", bob, updateMethod, &newsv1.UpdateNewsRequest{Id: "news-of-bob"}, ""},
+		{"owner deletes", bob, deleteMethod, &newsv1.DeleteNewsRequest{Id: "news-of-bob"}, ""},
+		{"non-owner updates", bob, updateMethod, &newsv1.UpdateNewsRequest{Id: "news-of-carol"}, "NOT_OWNER"},
+		{"non-owner deletes", bob, deleteMethod, &newsv1.DeleteNewsRequest{Id: "news-of-carol"}, "NOT_OWNER"},
+		{"owner hands news over", bob, updateMethod, &newsv1.UpdateNewsRequest{Id: "news-of-bob", Author: "carol"}, "NOT_OWNER"},
+		{"unknown id is left to the handler", bob, deleteMethod, &newsv1.DeleteNewsRequest{Id: "missing"}, ""},
+		{"admin updates any news", &auth.Principal{Subject: "alice", Method: "jwt"}, updateMethod, &newsv1.UpdateNewsRequest{Id: "news-of-carol"}, ""},
+		{"reader may not update", &auth.Principal{Subject: "bob", Method: "jwt"}, updateMethod, &newsv1.UpdateNewsRequest{Id: "news-of-bob"}, "METHOD_NOT_ALLOWED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callUnary(a, tt.principal, tt.method, tt.req)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("denied: %v", err)
+				}
+				return
+			}
+			if got := deniedReason(t, err); got != tt.wantReason {
+				t.Errorf("reason %q, want %q", got, tt.wantReason)
+			}
+		})
+	}
+}
+
+// fakeStream hands msgs to RecvMsg one at a time.
+type fakeStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []*newsv1.BulkCreateNewsRequest
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream) RecvMsg(m any) error {
+	if len(s.msgs) == 0 {
+		return io.EOF
+	}
+	proto.Merge(m.(*newsv1.BulkCreateNewsRequest), s.msgs[0])
+	s.msgs = s.msgs[1:]
+	return nil
+}
+
+func TestOwnedStream(t *testing.T) {
+	a := newAuthorizer(t, authors)
+	bob := &auth.Principal{Subject: "bob", Roles: []string{"editor"}, Method: "jwt"}
+	ss := &fakeStream{
+		ctx: auth.NewContext(context.Background(), bob),
+		msgs: []*newsv1.BulkCreateNewsRequest{
+			{News: &newsv1.CreateNewsRequest{Author: "bob"}},
+			{News: &newsv1.CreateNewsRequest{Author: "carol"}},
+			{News: &newsv1.CreateNewsRequest{Author: "bob"}},
+		},
+	}
+
+	var (
+		received []string
+		recvErr  error
+	)
+	err := a.Stream()(nil, ss, &grpc.StreamServerInfo{FullMethod: bulkMethod}, func(srv any, ss grpc.ServerStream) error {
+		for {
+			in := &newsv1.BulkCreateNewsRequest{}
+			if recvErr = ss.RecvMsg(in); recvErr != nil {
+				return recvErr
+			}
+			received = append(received, in.GetNews().GetAuthor())
+		}
+	})
+	if !slices.Equal(received, []string{"bob"}) {
+		t.Errorf("handler received news of %v, want only the first of bob", received)
+	}
+	if got := deniedReason(t, err); got != "NOT_OWNER" {
+		t.Errorf("reason %q, want NOT_OWNER", got)
+	}
+}
+
+func TestStreamCarriesRoles(t *testing.T) {
+	a := newAuthorizer(t, authors)
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		wantCode  codes.Code
+		wantRoles []string
+	}{
+		{"reader by default", &auth.Principal{Subject: "bob", Method: "jwt"}, codes.OK, []string{"reader"}},
+		{"admin by subject", &auth.Principal{Subject: "alice", Method: "jwt"}, codes.OK, []string{"admin", "reader"}},
+		{"key without scopes", &auth.Principal{Subject: "bob", Method: auth.MethodAPIKey}, codes.PermissionDenied, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen *auth.Principal
+			ss := &fakeStream{ctx: auth.NewContext(context.Background(), tt.principal)}
+			err := a.Stream()(nil, ss, &grpc.StreamServerInfo{FullMethod: getAllMethod}, func(srv any, ss grpc.ServerStream) error {
+				seen, _ = auth.FromContext(ss.Context())
+				return nil
+			})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code %v, want %v (%v)", got, tt.wantCode, err)
+			}
+			if err == nil && !slices.Equal(seen.Roles, tt.wantRoles) {
+				t.Errorf("handler saw roles %v, want %v", seen.Roles, tt.wantRoles)
+			}
+		})
+	}
+}
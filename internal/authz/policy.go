@@ -0,0 +1,132 @@
+// Package authz decides which authenticated principals may call which
+// methods, following a role based policy file.
+package authz
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy maps roles to the methods they may call. It is read from YAML, or
+// JSON, which YAML accepts as well:
+//
+//	roles:
+//	  reader:
+//	    allow: ["/news.v1.NewsService/GetNews", "/news.v1.NewsService/ListNews"]
+//	  editor:
+//	    allow: ["/news.v1.NewsService/CreateNews"]
+//	    allow_own: ["/news.v1.NewsService/UpdateNews"]
+//	subjects:
+//	  alice: [admin]
+//	default_roles: [reader]
+//
+// Method patterns are full gRPC method names, "/package.Service/*" for a
+// whole service or "*" for everything.
+type Policy struct {
+	Roles map[string]Role `yaml:"roles" json:"roles"`
+	// Subjects grants roles to principals by subject, on top of the roles
+	// they were authenticated with.
+	Subjects map[string][]string `yaml:"subjects" json:"subjects"`
+	// DefaultRoles are granted to every authenticated principal.
+	DefaultRoles []string `yaml:"default_roles" json:"default_roles"`
+}
+
+// Role lists the methods granted by a role.
+type Role struct {
+	// Allow grants the methods outright.
+	Allow []string `yaml:"allow" json:"allow"`
+	// AllowOwn grants the methods only on news authored by the principal.
+	AllowOwn []string `yaml:"allow_own" json:"allow_own"`
+}
+
+type decision int
+
+const (
+	deny decision = iota
+	allowOwn
+	allow
+)
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Policy
+	if err := yaml.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &p, nil
+}
+
+func (p *Policy) validate() error {
+	for name, role := range p.Roles {
+		for _, pattern := range slices.Concat(role.Allow, role.AllowOwn) {
+			if pattern != "*" && !strings.HasPrefix(pattern, "/") {
+				return fmt.Errorf("role %q: method %q must be a full method name, /package.Service/* or *", name, pattern)
+			}
+		}
+	}
+	for subject, roles := range p.Subjects {
+		for _, role := range roles {
+			if _, ok := p.Roles[role]; !ok {
+				return fmt.Errorf("subject %q: unknown role %q", subject, role)
+			}
+		}
+	}
+	for _, role := range p.DefaultRoles {
+		if _, ok := p.Roles[role]; !ok {
+			return fmt.Errorf("default_roles: unknown role %q", role)
+		}
+	}
+	return nil
+}
+
+// decide returns the strongest grant any role of the principal holds for
+// method. Roles unknown to the policy grant nothing.
+func (p *Policy) decide(principal *auth.Principal, method string) decision {
+	granted := deny
+	for _, name := range p.roles(principal) {
+		role, ok := p.Roles[name]
+		if !ok {
+			continue
+		}
+		if slices.ContainsFunc(role.Allow, func(pattern string) bool { return matchMethod(pattern, method) }) {
+			return allow
+		}
+		if slices.ContainsFunc(role.AllowOwn, func(pattern string) bool { return matchMethod(pattern, method) }) {
+			granted = allowOwn
+		}
+	}
+	return granted
+}
+
+// roles returns the roles of the principal along with those the policy
+// grants it by subject and by default. API keys only hold their scopes, so
+// a key never carries more than its owner chose to give it.
+func (p *Policy) roles(principal *auth.Principal) []string {
+	roles := slices.Clone(principal.Roles)
+	if principal.Method != auth.MethodAPIKey {
+		roles = slices.Concat(roles, p.Subjects[principal.Subject], p.DefaultRoles)
+	}
+	slices.Sort(roles)
+	return slices.Compact(roles)
+}
+
+func matchMethod(pattern, method string) bool {
+	if pattern == "*" || pattern == method {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "*")
+	return ok && strings.HasSuffix(prefix, "/") && strings.HasPrefix(method, prefix)
+}
@@ -0,0 +1,216 @@
+// Package certs loads TLS key pairs and CA bundles and keeps them current
+// when the files on disk are replaced, e.g. by a certificate rotation job.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Files names the PEM files to load. Cert and Key go together; CA is a
+// bundle of trusted roots. Any of them may be empty.
+type Files struct {
+	Cert, Key, CA string
+}
+
+// Reloader holds the current key pair and CA pool read from Files.
+type Reloader struct {
+	files Files
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// NewReloader loads the files once.
+func NewReloader(files Files) (*Reloader, error) {
+	if (files.Cert == "") != (files.Key == "") {
+		return nil, errors.New("certificate and key must be given together")
+	}
+	r := &Reloader{files: files}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload re-reads every file. On error the current material stays.
+func (r *Reloader) Reload() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	var cert *tls.Certificate
+	if r.files.Cert != "" {
+		pair, err := tls.LoadX509KeyPair(r.files.Cert, r.files.Key)
+		if err != nil {
+			return fmt.Errorf("load key pair: %w", err)
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if r.files.CA != "" {
+		raw, err := os.ReadFile(r.files.CA)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(raw) {
+			return fmt.Errorf("%s: no certificates found", r.files.CA)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.pool, r.modTimes = cert, pool, modTimes
+	return nil
+}
+
+// Watch reloads the files whenever one of their modification times
+// changes, checking every interval until ctx is done.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		modTimes, err := r.stat()
+		if err != nil {
+			log.Errorf("failed to stat certificates: %v", err)
+			continue
+		}
+		r.mu.Lock()
+		changed := false
+		for path, modTime := range modTimes {
+			changed = changed || !modTime.Equal(r.modTimes[path])
+		}
+		// Remember the attempt, so a broken file is reported once per change.
+		r.modTimes = modTimes
+		r.mu.Unlock()
+		if !changed {
+			continue
+		}
+
+		// A rotation job may still be writing the other file of the pair;
+		// the next change will trigger another attempt.
+		if err := r.Reload(); err != nil {
+			log.Errorf("failed to reload certificates, keeping the previous ones: %v", err)
+			continue
+		}
+		log.Info("TLS certificates reloaded")
+	}
+}
+
+func (r *Reloader) stat() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time, 3)
+	for _, path := range []string{r.files.Cert, r.files.Key, r.files.CA} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// Certificate returns the current key pair, or nil if none is configured.
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// CAPool returns the current trusted roots, or nil if none are configured.
+func (r *Reloader) CAPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
+
+// ServerConfig serves the reloader's certificate and, when a CA is
+// configured, verifies client certificates against it with clientAuth.
+// Every handshake sees the latest files.
+func ServerConfig(r *Reloader, clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert := r.Certificate()
+			if cert == nil {
+				return nil, errors.New("no server certificate configured")
+			}
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   []string{"h2"},
+			}
+			if pool := r.CAPool(); pool != nil {
+				cfg.ClientCAs = pool
+				cfg.ClientAuth = clientAuth
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// ClientConfig trusts the reloader's CA, or the system roots without one,
+// and presents its certificate when the server asks for one. Every
+// handshake sees the latest files, so a rotated CA applies to new
+// connections.
+func ClientConfig(r *Reloader, serverName string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if cert := r.Certificate(); cert != nil {
+				return cert, nil
+			}
+			return &tls.Certificate{}, nil
+		},
+	}
+	if r.files.CA != "" {
+		// RootCAs would pin the pool of this moment, so the default
+		// verification is replaced by one against the current pool.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyServer(cs, r.CAPool())
+		}
+	}
+	return cfg
+}
+
+// verifyServer does what crypto/tls does for a client with RootCAs set to
+// roots: the chain must lead to roots and the leaf must match the server
+// name.
+func verifyServer(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server sent no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
@@ -0,0 +1,137 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA is a CA that issues server certificates for tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	cert, key, der := sign(t, template, nil, nil)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// serverCert issues a certificate for dnsName.
+func (ca *testCA) serverCert(t *testing.T, dnsName string) tls.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsName},
+		DNSNames:    []string{dnsName},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	cert, key, der := sign(t, template, ca.cert, ca.key)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}
+}
+
+// sign creates a certificate from template, self-signed without a parent.
+func sign(t *testing.T, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key, der
+}
+
+// handshake connects with client to a server presenting cert.
+func handshake(t *testing.T, client *tls.Config, cert tls.Certificate) error {
+	t.Helper()
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// The client's verdict is what the test checks.
+		_ = conn.(*tls.Conn).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func TestClientConfigFollowsCARotation(t *testing.T) {
+	oldCA, newCA := newTestCA(t, "old CA"), newTestCA(t, "new CA")
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, oldCA.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReloader(Files{CA: caFile})
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	client := ClientConfig(r, "news.example.com")
+
+	if err := handshake(t, client, oldCA.serverCert(t, "news.example.com")); err != nil {
+		t.Fatalf("server of the trusted CA rejected: %v", err)
+	}
+	if err := handshake(t, client, oldCA.serverCert(t, "other.example.com")); err == nil {
+		t.Error("server certificate for another name accepted")
+	}
+	if err := handshake(t, client, newCA.serverCert(t, "news.example.com")); err == nil {
+		t.Error("server of an untrusted CA accepted")
+	}
+
+	if err := os.WriteFile(caFile, newCA.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if err := handshake(t, client, newCA.serverCert(t, "news.example.com")); err != nil {
+		t.Errorf("server of the rotated CA rejected: %v", err)
+	}
+	if err := handshake(t, client, oldCA.serverCert(t, "news.example.com")); err == nil {
+		t.Error("server of the retired CA still accepted")
+	}
+}
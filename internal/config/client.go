@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/sabuhigr/grpc-demo/internal/tracing"
+)
+
+// Client is the configuration of cmd/client.
+type Client struct {
+	// Addr is the server to dial, Timeout bounds the whole run.
+	Addr    string        `yaml:"addr" toml:"addr"`
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+
+	TLS       ClientTLS  `yaml:"tls" toml:"tls"`
+	Auth      ClientAuth `yaml:"auth" toml:"auth"`
+	Backoff   Backoff    `yaml:"backoff" toml:"backoff"`
+	Keepalive Keepalive  `yaml:"keepalive" toml:"keepalive"`
+	Logging   Logging    `yaml:"logging" toml:"logging"`
+	Tracing   Tracing    `yaml:"tracing" toml:"tracing"`
+}
+
+// ClientTLS enables TLS when CA or Cert is set.
+type ClientTLS struct {
+	CA         string `yaml:"ca" toml:"ca"`
+	Cert       string `yaml:"cert" toml:"cert"`
+	Key        string `yaml:"key" toml:"key"`
+	ServerName string `yaml:"server_name" toml:"server_name"`
+}
+
+// ClientAuth picks the credentials sent: Token, the content of TokenFile or a
+// JWT minted with JWTKey, in that order of preference.
+type ClientAuth struct {
+	Token       string        `yaml:"token" toml:"token" secret:"true"`
+	TokenFile   string        `yaml:"token_file" toml:"token_file"`
+	JWTKey      string        `yaml:"jwt_key" toml:"jwt_key"`
+	JWTKeyID    string        `yaml:"jwt_kid" toml:"jwt_kid"`
+	JWTSubject  string        `yaml:"jwt_subject" toml:"jwt_subject"`
+	JWTRoles    List          `yaml:"jwt_roles" toml:"jwt_roles"`
+	JWTIssuer   string        `yaml:"jwt_issuer" toml:"jwt_issuer"`
+	JWTAudience string        `yaml:"jwt_audience" toml:"jwt_audience"`
+	JWTTTL      time.Duration `yaml:"jwt_ttl" toml:"jwt_ttl"`
+}
+
+// Backoff tunes reconnects, see grpc.ConnectParams.
+type Backoff struct {
+	BaseDelay         time.Duration `yaml:"base_delay" toml:"base_delay"`
+	Multiplier        float64       `yaml:"multiplier" toml:"multiplier"`
+	MaxDelay          time.Duration `yaml:"max_delay" toml:"max_delay"`
+	MinConnectTimeout time.Duration `yaml:"min_connect_timeout" toml:"min_connect_timeout"`
+}
+
+// Keepalive tunes connection pings, see keepalive.ClientParameters.
+type Keepalive struct {
+	Time                time.Duration `yaml:"time" toml:"time"`
+	Timeout             time.Duration `yaml:"timeout" toml:"timeout"`
+	PermitWithoutStream bool          `yaml:"permit_without_stream" toml:"permit_without_stream"`
+}
+
+// DefaultClient returns the defaults of cmd/client.
+func DefaultClient() *Client {
+	return &Client{
+		Addr:    "127.0.0.1:8080",
+		Timeout: 10 * time.Second,
+		Auth: ClientAuth{
+			JWTSubject: "client",
+			JWTTTL:     time.Hour,
+		},
+		Backoff: Backoff{
+			BaseDelay:         time.Second,
+			Multiplier:        1.6,
+			MaxDelay:          120 * time.Second,
+			MinConnectTimeout: 5 * time.Second,
+		},
+		Keepalive: Keepalive{
+			Time:                10 * time.Second,
+			Timeout:             5 * time.Second,
+			PermitWithoutStream: true,
+		},
+		Logging: Logging{Level: "info", Format: LogFormatJSONPretty},
+		Tracing: Tracing{Exporter: tracing.ExporterNone, File: "client-traces.jsonl", SampleRatio: 1},
+	}
+}
+
+func (c *Client) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Addr, "addr", c.Addr, "address of the server")
+	fs.DurationVar(&c.Timeout, "timeout", c.Timeout, "deadline of the whole run")
+
+	fs.StringVar(&c.TLS.CA, "tls-ca", c.TLS.CA, "CA bundle to verify the server with; enables TLS")
+	fs.StringVar(&c.TLS.Cert, "tls-cert", c.TLS.Cert, "client certificate PEM file for mTLS")
+	fs.StringVar(&c.TLS.Key, "tls-key", c.TLS.Key, "client private key PEM file for mTLS")
+	fs.StringVar(&c.TLS.ServerName, "tls-server-name", c.TLS.ServerName, "expected server name, if it differs from the dialed host")
+
+	fs.StringVar(&c.Auth.Token, "token", c.Auth.Token, "bearer token to send")
+	fs.StringVar(&c.Auth.TokenFile, "token-file", c.Auth.TokenFile, "file holding the bearer token to send")
+	fs.StringVar(&c.Auth.JWTKey, "jwt-key", c.Auth.JWTKey, "PEM private key or HMAC secret file to mint a JWT with")
+	fs.StringVar(&c.Auth.JWTKeyID, "jwt-kid", c.Auth.JWTKeyID, "kid header of the minted JWT")
+	fs.StringVar(&c.Auth.JWTSubject, "jwt-subject", c.Auth.JWTSubject, "sub claim of the minted JWT")
+	fs.Var(&c.Auth.JWTRoles, "jwt-roles", "comma separated roles claim of the minted JWT")
+	fs.StringVar(&c.Auth.JWTIssuer, "jwt-issuer", c.Auth.JWTIssuer, "iss claim of the minted JWT")
+	fs.StringVar(&c.Auth.JWTAudience, "jwt-audience", c.Auth.JWTAudience, "aud claim of the minted JWT")
+	fs.DurationVar(&c.Auth.JWTTTL, "jwt-ttl", c.Auth.JWTTTL, "lifetime of the minted JWT")
+
+	fs.DurationVar(&c.Backoff.BaseDelay, "backoff-base-delay", c.Backoff.BaseDelay, "delay before the first reconnect")
+	fs.Float64Var(&c.Backoff.Multiplier, "backoff-multiplier", c.Backoff.Multiplier, "factor the reconnect delay grows by")
+	fs.DurationVar(&c.Backoff.MaxDelay, "backoff-max-delay", c.Backoff.MaxDelay, "upper bound of the reconnect delay")
+	fs.DurationVar(&c.Backoff.MinConnectTimeout, "min-connect-timeout", c.Backoff.MinConnectTimeout, "least time a connection attempt is given")
+
+	fs.DurationVar(&c.Keepalive.Time, "keepalive-time", c.Keepalive.Time, "idle time after which the connection is pinged")
+	fs.DurationVar(&c.Keepalive.Timeout, "keepalive-timeout", c.Keepalive.Timeout, "how long a ping may go unanswered before the connection is closed")
+	fs.BoolVar(&c.Keepalive.PermitWithoutStream, "keepalive-permit-without-stream", c.Keepalive.PermitWithoutStream, "ping even without active calls")
+
+	c.Logging.flags(fs)
+	c.Tracing.flags(fs, false)
+}
+
+func (c *Client) Validate() error {
+	errs := errors.Join(
+		validateAddr("addr", c.Addr, false),
+		positive("timeout", c.Timeout),
+		c.Logging.validate(),
+		c.Tracing.validate(),
+	)
+	if (c.TLS.Cert == "") != (c.TLS.Key == "") {
+		errs = errors.Join(errs, errors.New("a TLS client certificate and key go together"))
+	}
+	if c.Auth.JWTKey != "" {
+		errs = errors.Join(errs, positive("JWT TTL", c.Auth.JWTTTL))
+	}
+	errs = errors.Join(errs,
+		positive("backoff base delay", c.Backoff.BaseDelay),
+		positive("backoff max delay", c.Backoff.MaxDelay),
+		positive("min connect timeout", c.Backoff.MinConnectTimeout),
+		positive("keepalive time", c.Keepalive.Time),
+		positive("keepalive timeout", c.Keepalive.Timeout),
+	)
+	if c.Backoff.Multiplier < 1 {
+		errs = errors.Join(errs, errors.New("backoff multiplier must be at least 1"))
+	}
+	if c.Backoff.MaxDelay < c.Backoff.BaseDelay {
+		errs = errors.Join(errs, errors.New("backoff max delay is below the base delay"))
+	}
+	return errs
+}
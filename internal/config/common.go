@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+
+	"github.com/sabuhigr/grpc-demo/internal/tracing"
+	log "github.com/sirupsen/logrus"
+)
+
+// Log formats.
+const (
+	LogFormatJSON       = "json"
+	LogFormatJSONPretty = "json-pretty"
+	LogFormatText       = "text"
+)
+
+// Logging configures the standard logger.
+type Logging struct {
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
+}
+
+func (l *Logging) flags(fs *flag.FlagSet) {
+	fs.StringVar(&l.Level, "log-level", l.Level, "log level: trace, debug, info, warn or error")
+	fs.StringVar(&l.Format, "log-format", l.Format, "log format: json, json-pretty or text")
+}
+
+func (l *Logging) validate() error {
+	var errs error
+	if _, err := log.ParseLevel(l.Level); err != nil {
+		errs = errors.Join(errs, err)
+	}
+	switch l.Format {
+	case LogFormatJSON, LogFormatJSONPretty, LogFormatText:
+	default:
+		errs = errors.Join(errs, fmt.Errorf("unknown log format %q", l.Format))
+	}
+	return errs
+}
+
+// Apply configures the standard logger.
+func (l Logging) Apply() {
+	level, err := log.ParseLevel(l.Level)
+	if err == nil {
+		log.SetLevel(level)
+	}
+	switch l.Format {
+	case LogFormatJSON:
+		log.SetFormatter(&log.JSONFormatter{})
+	case LogFormatJSONPretty:
+		log.SetFormatter(&log.JSONFormatter{PrettyPrint: true})
+	case LogFormatText:
+		log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	}
+}
+
+// Tracing configures span export, see tracing.Options.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`
+	File        string  `yaml:"file" toml:"file"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+func (t *Tracing) flags(fs *flag.FlagSet, sampling bool) {
+	fs.StringVar(&t.Exporter, "trace-exporter", t.Exporter, "span exporter: none, stdout or file")
+	fs.StringVar(&t.File, "trace-file", t.File, "OTLP JSON file of the file exporter")
+	if sampling {
+		fs.Float64Var(&t.SampleRatio, "trace-sample-ratio", t.SampleRatio, "fraction of new traces recorded; traces started by callers follow their decision")
+	}
+}
+
+func (t *Tracing) validate() error {
+	var errs error
+	switch t.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterFile:
+		if t.File == "" {
+			errs = errors.Join(errs, errors.New("the file trace exporter needs a trace file"))
+		}
+	default:
+		errs = errors.Join(errs, fmt.Errorf("unknown trace exporter %q", t.Exporter))
+	}
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		errs = errors.Join(errs, fmt.Errorf("trace sample ratio %g is not within [0, 1]", t.SampleRatio))
+	}
+	return errs
+}
+
+// Options returns the tracing options of the named service.
+func (t Tracing) Options(service string) tracing.Options {
+	return tracing.Options{
+		ServiceName: service,
+		Exporter:    t.Exporter,
+		File:        t.File,
+		SampleRatio: t.SampleRatio,
+	}
+}
+
+func validateAddr(name, addr string, optional bool) error {
+	if addr == "" && optional {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("%s %q: %w", name, addr, err)
+	}
+	return nil
+}
+
+func positive[T ~int64 | ~float64](name string, v T) error {
+	if v <= 0 {
+		return fmt.Errorf("%s must be positive", name)
+	}
+	return nil
+}
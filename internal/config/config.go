@@ -0,0 +1,221 @@
+// Package config loads the configuration of the server and the client from,
+// in increasing precedence, a YAML or TOML file, environment variables and
+// command line flags.
+//
+// Every setting has a flag. Its environment variable is the flag name upper
+// cased with dashes turned into underscores, behind the binary's prefix:
+// -tls-cert of the server is NEWS_SERVER_TLS_CERT.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variable prefixes of the binaries.
+const (
+	ServerEnvPrefix = "NEWS_SERVER_"
+	ClientEnvPrefix = "NEWS_CLIENT_"
+)
+
+// redacted replaces secrets in printed configs.
+const redacted = "[REDACTED]"
+
+// Config is implemented by the configuration of each binary.
+type Config interface {
+	// Flags binds every setting to a flag of fs, defaulting to its current
+	// value.
+	Flags(fs *flag.FlagSet)
+	// Validate reports every invalid setting.
+	Validate() error
+}
+
+// Load fills cfg, which holds the defaults, from the config file, the
+// environment and args, then validates it. The file is named by -config or
+// the <prefix>CONFIG variable. With -print-config, the effective
+// configuration is printed and the process exits.
+func Load(name, envPrefix string, args []string, cfg Config) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	cfg.Flags(fs)
+	path := fs.String("config", "", "YAML or TOML config file, overridden by environment variables and flags (env "+envPrefix+"CONFIG)")
+	printConfig := fs.Bool("print-config", false, "print the effective configuration and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Flags win, so remember what was set to apply it again last.
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	if _, ok := set["config"]; !ok {
+		*path = os.Getenv(envPrefix + "CONFIG")
+	}
+	if *path != "" {
+		if err := readFile(*path, cfg); err != nil {
+			return err
+		}
+	}
+
+	var errs error
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || f.Name == "print-config" {
+			return
+		}
+		env := EnvName(envPrefix, f.Name)
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", env, err))
+		}
+	})
+	if errs != nil {
+		return errs
+	}
+	for name, value := range set {
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("-%s: %w", name, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if *printConfig {
+		if err := Print(os.Stdout, cfg); err != nil {
+			return err
+		}
+		os.Exit(0)
+	}
+	return nil
+}
+
+// EnvName is the environment variable of a flag.
+func EnvName(prefix, flagName string) string {
+	return prefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// readFile decodes the file onto cfg. Unknown keys are errors, so typos do
+// not go unnoticed.
+func readFile(path string, cfg Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown keys %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("%s: unknown config format %q, want .yaml, .yml or .toml", path, ext)
+	}
+	return nil
+}
+
+// Print writes cfg as YAML, with fields tagged secret:"true" redacted.
+func Print(w io.Writer, cfg Config) error {
+	v := reflect.New(reflect.TypeOf(cfg).Elem())
+	v.Elem().Set(reflect.ValueOf(cfg).Elem())
+	redact(v.Elem())
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v.Interface()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func redact(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct:
+			redact(field)
+		case field.Kind() == reflect.String && v.Type().Field(i).Tag.Get("secret") == "true" && field.String() != "":
+			field.SetString(redacted)
+		}
+	}
+}
+
+// Reload copies the settings tagged reload:"true", or nested in a field so
+// tagged, from next into cur. It returns the paths of the changed settings:
+// those applied, and those that need a restart and keep their value in cur.
+func Reload(cur, next Config) (applied, restart []string) {
+	reload(reflect.ValueOf(cur).Elem(), reflect.ValueOf(next).Elem(), "", &applied, &restart)
+	return applied, restart
+}
+
+func reload(cur, next reflect.Value, prefix string, applied, restart *[]string) {
+	for i := 0; i < cur.NumField(); i++ {
+		field := cur.Type().Field(i)
+		path := prefix + strings.Split(field.Tag.Get("yaml"), ",")[0]
+		switch {
+		case field.Tag.Get("reload") == "true":
+			*applied = append(*applied, changed(cur.Field(i), next.Field(i), path)...)
+			cur.Field(i).Set(next.Field(i))
+		case field.Type.Kind() == reflect.Struct:
+			reload(cur.Field(i), next.Field(i), path+".", applied, restart)
+		default:
+			*restart = append(*restart, changed(cur.Field(i), next.Field(i), path)...)
+		}
+	}
+}
+
+// changed returns the paths of the settings that differ between a and b.
+func changed(a, b reflect.Value, path string) []string {
+	if a.Kind() != reflect.Struct {
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return nil
+		}
+		return []string{path}
+	}
+	var paths []string
+	for i := 0; i < a.NumField(); i++ {
+		name := strings.Split(a.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		paths = append(paths, changed(a.Field(i), b.Field(i), path+"."+name)...)
+	}
+	return paths
+}
+
+// List is a list setting, given as a comma separated flag or environment
+// variable and as a sequence in files.
+type List []string
+
+func (l *List) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *List) Set(s string) error {
+	*l = nil
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
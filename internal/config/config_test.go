@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to name in a temporary directory and returns
+// its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := writeFile(t, "server.yaml", `
+listen: 127.0.0.1:7000
+limits:
+  rate_limit: "1:1"
+  create_news_daily_quota: 10
+logging:
+  level: warn
+`)
+	tomlFile := writeFile(t, "server.toml", `
+listen = "127.0.0.1:7000"
+
+[limits]
+rate_limit = "1:1"
+create_news_daily_quota = 10
+
+[logging]
+level = "warn"
+`)
+
+	for _, path := range []string{yamlFile, tomlFile} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			t.Setenv("NEWS_SERVER_RATE_LIMIT", "2:2")
+			t.Setenv("NEWS_SERVER_LOG_LEVEL", "error")
+			cfg := DefaultServer()
+			args := []string{"-config", path, "-log-level", "debug"}
+			if err := Load("server", ServerEnvPrefix, args, cfg); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			tests := []struct {
+				setting   string
+				got, want any
+			}{
+				{"default", cfg.Limits.IdempotencyRetention, 24 * time.Hour},
+				{"file over default", cfg.Listen, "127.0.0.1:7000"},
+				{"file over default", cfg.Limits.CreateNewsDailyQuota, int64(10)},
+				{"env over file", cfg.Limits.RateLimit, "2:2"},
+				{"flag over env and file", cfg.Logging.Level, "debug"},
+			}
+			for _, tt := range tests {
+				if tt.got != tt.want {
+					t.Errorf("%s: got %v, want %v", tt.setting, tt.got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv("NEWS_SERVER_CONFIG", writeFile(t, "server.yml", "listen: 127.0.0.1:7000\n"))
+	cfg := DefaultServer()
+	if err := Load("server", ServerEnvPrefix, nil, cfg); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Listen != "127.0.0.1:7000" {
+		t.Errorf("listen %q, want the value of the file named by NEWS_SERVER_CONFIG", cfg.Listen)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		wantErr string
+	}{
+		{"unknown yaml key", nil, []string{"-config", writeFile(t, "typo.yaml", "lisen: :1\n")}, "lisen"},
+		{"unknown toml key", nil, []string{"-config", writeFile(t, "typo.toml", "lisen = \":1\"\n")}, "unknown keys"},
+		{"unknown format", nil, []string{"-config", writeFile(t, "server.json", "{}")}, "unknown config format"},
+		{"bad env value", map[string]string{"NEWS_SERVER_CREATE_NEWS_DAILY_QUOTA": "many"}, nil, "NEWS_SERVER_CREATE_NEWS_DAILY_QUOTA"},
+		{"invalid setting", nil, []string{"-log-level", "loud"}, "invalid configuration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			err := Load("server", ServerEnvPrefix, tt.args, DefaultServer())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load: %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	server := DefaultServer()
+	server.Auth.StaticToken = "s3cret-server-token"
+	client := DefaultClient()
+	client.Auth.Token = "s3cret-client-token"
+
+	for _, cfg := range []Config{server, client} {
+		var out bytes.Buffer
+		if err := Print(&out, cfg); err != nil {
+			t.Fatalf("Print: %v", err)
+		}
+		if strings.Contains(out.String(), "s3cret") {
+			t.Errorf("printed config leaks a secret:\n%s", out.String())
+		}
+		if !strings.Contains(out.String(), redacted) {
+			t.Errorf("printed config does not show the secret is set:\n%s", out.String())
+		}
+	}
+	if server.Auth.StaticToken != "s3cret-server-token" || client.Auth.Token != "s3cret-client-token" {
+		t.Error("Print redacted the config it was given")
+	}
+
+	// An unset secret stays empty, so it is clear it is unset.
+	var out bytes.Buffer
+	if err := Print(&out, DefaultServer()); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+	if strings.Contains(out.String(), redacted) {
+		t.Errorf("unset secret printed as set:\n%s", out.String())
+	}
+}
+
+func TestReload(t *testing.T) {
+	cur := DefaultServer()
+	next := DefaultServer()
+	next.Logging.Level = "debug"
+	next.DebugErrors = true
+	next.Limits.RateLimit = "5:10"
+	next.Limits.MethodRateLimits = List{"/news.v1.NewsService/CreateNews=1:1"}
+	next.Listen = "127.0.0.1:7000"
+	next.Limits.IdempotencyRetention = time.Hour
+	next.TLS.Cert = "server.pem"
+
+	applied, restart := Reload(cur, next)
+
+	wantApplied := []string{"limits.rate_limit", "limits.method_rate_limits", "logging.level", "debug_errors"}
+	if !slices.Equal(applied, wantApplied) {
+		t.Errorf("applied %v, want %v", applied, wantApplied)
+	}
+	wantRestart := []string{"listen", "tls.cert", "limits.idempotency_retention"}
+	if !slices.Equal(restart, wantRestart) {
+		t.Errorf("restart required for %v, want %v", restart, wantRestart)
+	}
+
+	want := DefaultServer()
+	want.Logging.Level = "debug"
+	want.DebugErrors = true
+	want.Limits.RateLimit = "5:10"
+	want.Limits.MethodRateLimits = List{"/news.v1.NewsService/CreateNews=1:1"}
+	if !reflect.DeepEqual(cur, want) {
+		t.Errorf("after Reload got %+v, want only the reloadable settings changed: %+v", cur, want)
+	}
+
+	if applied, restart := Reload(cur, next); len(applied) != 0 || !slices.Equal(restart, wantRestart) {
+		t.Errorf("second Reload applied %v and needs a restart for %v, want nothing applied", applied, restart)
+	}
+}
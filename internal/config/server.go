@@ -0,0 +1,226 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sabuhigr/grpc-demo/internal/filestore"
+	"github.com/sabuhigr/grpc-demo/internal/ratelimit"
+	"github.com/sabuhigr/grpc-demo/internal/tracing"
+)
+
+// Server is the configuration of cmd/server. Settings tagged reload:"true"
+// can be changed while it runs, see Reload.
+type Server struct {
+	// Listen is the address of the gRPC listener, HTTPListen the one of the
+	// REST gateway, Connect and gRPC-Web, and MetricsListen the one serving
+	// /metrics. An empty HTTPListen or MetricsListen disables that listener.
+	Listen        string `yaml:"listen" toml:"listen"`
+	HTTPListen    string `yaml:"http_listen" toml:"http_listen"`
+	MetricsListen string `yaml:"metrics_listen" toml:"metrics_listen"`
+
+	// CORSOrigins are the browser origins allowed to call the HTTP listener,
+	// e.g. https://app.example.com; * allows any.
+	CORSOrigins List `yaml:"cors_origins" toml:"cors_origins"`
+
+	TLS      ServerTLS  `yaml:"tls" toml:"tls"`
+	Auth     ServerAuth `yaml:"auth" toml:"auth"`
+	Storage  Storage    `yaml:"storage" toml:"storage"`
+	Limits   Limits     `yaml:"limits" toml:"limits"`
+	Logging  Logging    `yaml:"logging" toml:"logging" reload:"true"`
+	Tracing  Tracing    `yaml:"tracing" toml:"tracing"`
+	Shutdown Shutdown   `yaml:"shutdown" toml:"shutdown"`
+
+	// DebugErrors sends the value and stack of recovered panics to callers.
+	DebugErrors bool `yaml:"debug_errors" toml:"debug_errors" reload:"true"`
+}
+
+// ServerTLS enables TLS when Cert is set, and mTLS when ClientCA is set too.
+type ServerTLS struct {
+	Cert              string        `yaml:"cert" toml:"cert"`
+	Key               string        `yaml:"key" toml:"key"`
+	ClientCA          string        `yaml:"client_ca" toml:"client_ca"`
+	RequireClientCert bool          `yaml:"require_client_cert" toml:"require_client_cert"`
+	ReloadInterval    time.Duration `yaml:"reload_interval" toml:"reload_interval"`
+}
+
+// ServerAuth configures authentication and authorization.
+type ServerAuth struct {
+	// JWTKeys are verification key files, each optionally named kid=path.
+	JWTKeys     List          `yaml:"jwt_keys" toml:"jwt_keys"`
+	JWKS        string        `yaml:"jwt_jwks" toml:"jwt_jwks"`
+	JWTIssuer   string        `yaml:"jwt_issuer" toml:"jwt_issuer"`
+	JWTAudience string        `yaml:"jwt_audience" toml:"jwt_audience"`
+	JWTLeeway   time.Duration `yaml:"jwt_leeway" toml:"jwt_leeway"`
+	StaticToken string        `yaml:"static_token" toml:"static_token" secret:"true"`
+
+	PolicyFile           string        `yaml:"policy_file" toml:"policy_file"`
+	PolicyReloadInterval time.Duration `yaml:"policy_reload_interval" toml:"policy_reload_interval"`
+}
+
+// Storage picks and tunes the store backend.
+type Storage struct {
+	Backend          string        `yaml:"backend" toml:"backend"`
+	DataDir          string        `yaml:"data_dir" toml:"data_dir"`
+	Fsync            string        `yaml:"fsync" toml:"fsync"`
+	FsyncInterval    time.Duration `yaml:"fsync_interval" toml:"fsync_interval"`
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" toml:"snapshot_interval"`
+}
+
+// Limits configures rate limits, quotas and idempotency.
+type Limits struct {
+	// RateLimit is the default rate:burst, MethodRateLimits overrides it as
+	// /pkg.Service/Method=rate:burst. An empty RateLimit disables it.
+	RateLimit            string        `yaml:"rate_limit" toml:"rate_limit" reload:"true"`
+	MethodRateLimits     List          `yaml:"method_rate_limits" toml:"method_rate_limits" reload:"true"`
+	CreateNewsDailyQuota int64         `yaml:"create_news_daily_quota" toml:"create_news_daily_quota" reload:"true"`
+	IdempotencyRetention time.Duration `yaml:"idempotency_retention" toml:"idempotency_retention"`
+}
+
+// Shutdown times the graceful shutdown.
+type Shutdown struct {
+	Drain   time.Duration `yaml:"drain" toml:"drain"`
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// DefaultServer returns the defaults of cmd/server.
+func DefaultServer() *Server {
+	return &Server{
+		Listen:        "127.0.0.1:8080",
+		HTTPListen:    "127.0.0.1:8081",
+		MetricsListen: "127.0.0.1:9090",
+		TLS:           ServerTLS{ReloadInterval: 10 * time.Second},
+		Auth: ServerAuth{
+			JWTLeeway:            30 * time.Second,
+			PolicyReloadInterval: 5 * time.Second,
+		},
+		Storage: Storage{
+			Backend:          "memory",
+			DataDir:          "data",
+			Fsync:            "always",
+			FsyncInterval:    time.Second,
+			SnapshotInterval: 5 * time.Minute,
+		},
+		Limits: Limits{
+			RateLimit:            "20:40",
+			CreateNewsDailyQuota: 1000,
+			IdempotencyRetention: 24 * time.Hour,
+		},
+		Logging:  Logging{Level: "info", Format: LogFormatJSON},
+		Tracing:  Tracing{Exporter: tracing.ExporterNone, File: "traces.jsonl", SampleRatio: 1},
+		Shutdown: Shutdown{Drain: 5 * time.Second, Timeout: 30 * time.Second},
+	}
+}
+
+func (c *Server) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Listen, "listen", c.Listen, "address of the gRPC listener")
+	fs.StringVar(&c.HTTPListen, "http-addr", c.HTTPListen, "address of the HTTP listener serving the REST gateway, Connect and gRPC-Web; empty disables it")
+	fs.Var(&c.CORSOrigins, "cors-origins", "comma separated browser origins allowed to call the HTTP listener; * allows any")
+	fs.StringVar(&c.MetricsListen, "metrics-addr", c.MetricsListen, "address of the HTTP listener serving /metrics; empty disables it")
+
+	fs.StringVar(&c.TLS.Cert, "tls-cert", c.TLS.Cert, "server certificate PEM file; enables TLS")
+	fs.StringVar(&c.TLS.Key, "tls-key", c.TLS.Key, "server private key PEM file")
+	fs.StringVar(&c.TLS.ClientCA, "tls-client-ca", c.TLS.ClientCA, "CA bundle to verify client certificates with; enables mTLS")
+	fs.BoolVar(&c.TLS.RequireClientCert, "tls-require-client-cert", c.TLS.RequireClientCert, "reject connections without a client certificate")
+	fs.DurationVar(&c.TLS.ReloadInterval, "tls-reload-interval", c.TLS.ReloadInterval, "how often certificate files are checked for changes")
+
+	fs.Var(&c.Auth.JWTKeys, "jwt-keys", "comma separated JWT verification key files, each optionally prefixed with kid=; PEM keys or an HMAC secret")
+	fs.StringVar(&c.Auth.JWKS, "jwt-jwks", c.Auth.JWKS, "JSON Web Key Set file with JWT verification keys")
+	fs.StringVar(&c.Auth.JWTIssuer, "jwt-issuer", c.Auth.JWTIssuer, "required iss claim of JWTs")
+	fs.StringVar(&c.Auth.JWTAudience, "jwt-audience", c.Auth.JWTAudience, "required aud claim of JWTs")
+	fs.DurationVar(&c.Auth.JWTLeeway, "jwt-leeway", c.Auth.JWTLeeway, "tolerated clock skew on JWT exp and nbf")
+	fs.StringVar(&c.Auth.StaticToken, "static-token", c.Auth.StaticToken, "legacy shared bearer token; empty disables it")
+	fs.StringVar(&c.Auth.PolicyFile, "authz-policy", c.Auth.PolicyFile, "role based authorization policy file (YAML or JSON); empty allows every authenticated call")
+	fs.DurationVar(&c.Auth.PolicyReloadInterval, "authz-reload-interval", c.Auth.PolicyReloadInterval, "how often the authorization policy file is checked for changes")
+
+	fs.StringVar(&c.Storage.Backend, "store", c.Storage.Backend, "storage backend: memory or file")
+	fs.StringVar(&c.Storage.DataDir, "data-dir", c.Storage.DataDir, "directory of the file store")
+	fs.StringVar(&c.Storage.Fsync, "fsync", c.Storage.Fsync, "file store fsync policy: always, interval or never")
+	fs.DurationVar(&c.Storage.FsyncInterval, "fsync-interval", c.Storage.FsyncInterval, "file store fsync period under -fsync=interval")
+	fs.DurationVar(&c.Storage.SnapshotInterval, "snapshot-interval", c.Storage.SnapshotInterval, "file store snapshot period")
+
+	fs.StringVar(&c.Limits.RateLimit, "rate-limit", c.Limits.RateLimit, "default rate:burst per principal, method and peer IP; empty disables it")
+	fs.Var(&c.Limits.MethodRateLimits, "rate-limit-methods", "comma separated per-method overrides as /pkg.Service/Method=rate:burst")
+	fs.Int64Var(&c.Limits.CreateNewsDailyQuota, "create-news-daily-quota", c.Limits.CreateNewsDailyQuota, "successful CreateNews calls allowed per principal and UTC day; 0 disables it")
+	fs.DurationVar(&c.Limits.IdempotencyRetention, "idempotency-retention", c.Limits.IdempotencyRetention, "how long CreateNews responses are replayed for a repeated idempotency-key")
+
+	c.Logging.flags(fs)
+	c.Tracing.flags(fs, true)
+
+	fs.DurationVar(&c.Shutdown.Drain, "shutdown-drain", c.Shutdown.Drain, "how long health checks report NOT_SERVING before the server stops accepting calls")
+	fs.DurationVar(&c.Shutdown.Timeout, "shutdown-timeout", c.Shutdown.Timeout, "how long in-flight calls may take to finish before connections are closed")
+	fs.BoolVar(&c.DebugErrors, "debug-errors", c.DebugErrors, "send the panic value and stack trace of recovered panics to callers")
+}
+
+func (c *Server) Validate() error {
+	errs := errors.Join(
+		validateAddr("listen", c.Listen, false),
+		validateAddr("HTTP listen", c.HTTPListen, true),
+		validateAddr("metrics listen", c.MetricsListen, true),
+		c.Logging.validate(),
+		c.Tracing.validate(),
+	)
+
+	switch {
+	case c.TLS.Cert != "" && c.TLS.Key == "":
+		errs = errors.Join(errs, errors.New("a TLS certificate needs a key"))
+	case c.TLS.Cert == "" && c.TLS.ClientCA != "":
+		errs = errors.Join(errs, errors.New("a TLS client CA needs a server certificate"))
+	}
+	if c.TLS.RequireClientCert && c.TLS.ClientCA == "" {
+		errs = errors.Join(errs, errors.New("requiring client certificates needs a TLS client CA"))
+	}
+	if c.TLS.Cert != "" {
+		errs = errors.Join(errs, positive("TLS reload interval", c.TLS.ReloadInterval))
+	}
+	for _, origin := range c.CORSOrigins {
+		if u, err := url.Parse(origin); origin != "*" && (err != nil || u.Scheme == "" || u.Host == "" || u.Path != "") {
+			errs = errors.Join(errs, fmt.Errorf("invalid CORS origin %q, want scheme://host[:port] or *", origin))
+		}
+	}
+	if c.Auth.JWTLeeway < 0 {
+		errs = errors.Join(errs, errors.New("JWT leeway cannot be negative"))
+	}
+	if c.Auth.PolicyFile != "" {
+		errs = errors.Join(errs, positive("policy reload interval", c.Auth.PolicyReloadInterval))
+	}
+
+	switch c.Storage.Backend {
+	case "memory":
+	case "file":
+		if c.Storage.DataDir == "" {
+			errs = errors.Join(errs, errors.New("the file store needs a data directory"))
+		}
+		if _, err := filestore.ParseSyncPolicy(c.Storage.Fsync); err != nil {
+			errs = errors.Join(errs, err)
+		}
+		errs = errors.Join(errs,
+			positive("fsync interval", c.Storage.FsyncInterval),
+			positive("snapshot interval", c.Storage.SnapshotInterval),
+		)
+	default:
+		errs = errors.Join(errs, fmt.Errorf("unknown store %q, want memory or file", c.Storage.Backend))
+	}
+
+	if c.Limits.RateLimit != "" {
+		if _, err := ratelimit.ParseLimit(c.Limits.RateLimit); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	if _, err := ratelimit.ParseMethodLimits(strings.Join(c.Limits.MethodRateLimits, ",")); err != nil {
+		errs = errors.Join(errs, err)
+	}
+	if c.Limits.CreateNewsDailyQuota < 0 {
+		errs = errors.Join(errs, errors.New("CreateNews daily quota cannot be negative"))
+	}
+	errs = errors.Join(errs, positive("idempotency retention", c.Limits.IdempotencyRetention))
+
+	if c.Shutdown.Drain < 0 || c.Shutdown.Timeout < 0 {
+		errs = errors.Join(errs, errors.New("shutdown durations cannot be negative"))
+	}
+	return errs
+}
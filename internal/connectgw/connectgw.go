@@ -0,0 +1,235 @@
+// Package connectgw serves NewsService and ApiKeyService over the Connect,
+// gRPC-Web and gRPC protocols, on HTTP/1.1 and HTTP/2, for browsers and other
+// HTTP clients. Like the REST gateway, calls are forwarded to the server over
+// a gRPC connection, so they pass the same interceptors as native calls.
+// Errors keep their code, message and details.
+package connectgw
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/api/news/v1/newsv1connect"
+	"github.com/sabuhigr/grpc-demo/internal/idempotency"
+	"github.com/sabuhigr/grpc-demo/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// forwardedHeaders are passed to the server as metadata of the same name.
+var forwardedHeaders = []string{"authorization", logging.RequestIDHeader, idempotency.KeyHeader}
+
+// Headers lists the request headers clients send, and ExposedHeaders the
+// response headers they read, for CORS.
+var (
+	Headers = []string{
+		"Content-Type", "Authorization", "X-Request-Id", "Idempotency-Key",
+		"Connect-Protocol-Version", "Connect-Timeout-Ms", "Grpc-Timeout",
+		"X-Grpc-Web", "X-User-Agent",
+	}
+	ExposedHeaders = []string{
+		"X-Request-Id", "Idempotency-Replayed",
+		"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin",
+	}
+)
+
+// NewHandler returns the path prefix and HTTP handler of NewsService,
+// calling the server over conn.
+func NewHandler(conn grpc.ClientConnInterface) (string, http.Handler) {
+	return newsv1connect.NewNewsServiceHandler(&proxy{client: newsv1.NewNewsServiceClient(conn)})
+}
+
+// proxy implements NewsService by forwarding every call to client.
+type proxy struct {
+	client newsv1.NewsServiceClient
+}
+
+func (p *proxy) CreateNews(ctx context.Context, req *connect.Request[newsv1.CreateNewsRequest]) (*connect.Response[newsv1.CreateNewsResponse], error) {
+	return unary(ctx, req, p.client.CreateNews)
+}
+
+func (p *proxy) GetNews(ctx context.Context, req *connect.Request[newsv1.GetNewsRequest]) (*connect.Response[newsv1.GetNewsResponse], error) {
+	return unary(ctx, req, p.client.GetNews)
+}
+
+func (p *proxy) BulkCreateNews(ctx context.Context, stream *connect.ClientStream[newsv1.BulkCreateNewsRequest]) (*connect.Response[newsv1.BulkCreateNewsResponse], error) {
+	ctx, cancel := context.WithCancel(outgoing(ctx, stream.RequestHeader()))
+	defer cancel()
+	upstream, err := p.client.BulkCreateNews(ctx)
+	if err != nil {
+		return nil, toConnect(err, nil, nil)
+	}
+	for stream.Receive() {
+		// io.EOF means the server ended the call; CloseAndRecv returns why.
+		if err := upstream.Send(stream.Msg()); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, toConnect(err, nil, nil)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+	res, err := upstream.CloseAndRecv()
+	header, _ := upstream.Header()
+	if err != nil {
+		return nil, toConnect(err, header, upstream.Trailer())
+	}
+	resp := connect.NewResponse(res)
+	copyMetadata(resp.Header(), header)
+	copyMetadata(resp.Trailer(), upstream.Trailer())
+	return resp, nil
+}
+
+func (p *proxy) GetAll(ctx context.Context, req *connect.Request[emptypb.Empty], stream *connect.ServerStream[newsv1.GetNewsResponse]) error {
+	return serverStream(ctx, req, stream, p.client.GetAll)
+}
+
+func (p *proxy) UpdateNews(ctx context.Context, req *connect.Request[newsv1.UpdateNewsRequest]) (*connect.Response[newsv1.UpdateNewsResponse], error) {
+	return unary(ctx, req, p.client.UpdateNews)
+}
+
+func (p *proxy) DeleteNews(ctx context.Context, req *connect.Request[newsv1.DeleteNewsRequest]) (*connect.Response[newsv1.DeleteNewsResponse], error) {
+	return unary(ctx, req, p.client.DeleteNews)
+}
+
+func (p *proxy) RestoreNews(ctx context.Context, req *connect.Request[newsv1.RestoreNewsRequest]) (*connect.Response[newsv1.RestoreNewsResponse], error) {
+	return unary(ctx, req, p.client.RestoreNews)
+}
+
+func (p *proxy) PurgeNews(ctx context.Context, req *connect.Request[newsv1.PurgeNewsRequest]) (*connect.Response[newsv1.PurgeNewsResponse], error) {
+	return unary(ctx, req, p.client.PurgeNews)
+}
+
+func (p *proxy) ListNews(ctx context.Context, req *connect.Request[newsv1.ListNewsRequest]) (*connect.Response[newsv1.ListNewsResponse], error) {
+	return unary(ctx, req, p.client.ListNews)
+}
+
+func (p *proxy) WatchNews(ctx context.Context, req *connect.Request[newsv1.WatchNewsRequest], stream *connect.ServerStream[newsv1.WatchNewsResponse]) error {
+	return serverStream(ctx, req, stream, p.client.WatchNews)
+}
+
+// NewAPIKeyHandler returns the path prefix and HTTP handler of
+// ApiKeyService, calling the server over conn.
+func NewAPIKeyHandler(conn grpc.ClientConnInterface) (string, http.Handler) {
+	return newsv1connect.NewApiKeyServiceHandler(&apiKeyProxy{client: newsv1.NewApiKeyServiceClient(conn)})
+}
+
+// apiKeyProxy implements ApiKeyService by forwarding every call to client.
+type apiKeyProxy struct {
+	client newsv1.ApiKeyServiceClient
+}
+
+func (p *apiKeyProxy) CreateApiKey(ctx context.Context, req *connect.Request[newsv1.CreateApiKeyRequest]) (*connect.Response[newsv1.CreateApiKeyResponse], error) {
+	return unary(ctx, req, p.client.CreateApiKey)
+}
+
+func (p *apiKeyProxy) ListApiKeys(ctx context.Context, req *connect.Request[newsv1.ListApiKeysRequest]) (*connect.Response[newsv1.ListApiKeysResponse], error) {
+	return unary(ctx, req, p.client.ListApiKeys)
+}
+
+func (p *apiKeyProxy) RevokeApiKey(ctx context.Context, req *connect.Request[newsv1.RevokeApiKeyRequest]) (*connect.Response[newsv1.RevokeApiKeyResponse], error) {
+	return unary(ctx, req, p.client.RevokeApiKey)
+}
+
+func (p *apiKeyProxy) RotateApiKey(ctx context.Context, req *connect.Request[newsv1.RotateApiKeyRequest]) (*connect.Response[newsv1.RotateApiKeyResponse], error) {
+	return unary(ctx, req, p.client.RotateApiKey)
+}
+
+// unary forwards a unary call.
+func unary[Req, Res any](ctx context.Context, req *connect.Request[Req], call func(context.Context, *Req, ...grpc.CallOption) (*Res, error)) (*connect.Response[Res], error) {
+	var header, trailer metadata.MD
+	res, err := call(outgoing(ctx, req.Header()), req.Msg, grpc.Header(&header), grpc.Trailer(&trailer))
+	if err != nil {
+		return nil, toConnect(err, header, trailer)
+	}
+	resp := connect.NewResponse(res)
+	copyMetadata(resp.Header(), header)
+	copyMetadata(resp.Trailer(), trailer)
+	return resp, nil
+}
+
+// serverStream forwards a server streaming call, sending each message as
+// soon as it arrives.
+func serverStream[Req, Res any](ctx context.Context, req *connect.Request[Req], stream *connect.ServerStream[Res], call func(context.Context, *Req, ...grpc.CallOption) (grpc.ServerStreamingClient[Res], error)) error {
+	ctx, cancel := context.WithCancel(outgoing(ctx, req.Header()))
+	defer cancel()
+	upstream, err := call(ctx, req.Msg)
+	if err != nil {
+		return toConnect(err, nil, nil)
+	}
+	// A failed call may end without headers; Recv then returns its error.
+	if header, err := upstream.Header(); err == nil {
+		copyMetadata(stream.ResponseHeader(), header)
+	}
+	for {
+		msg, err := upstream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return toConnect(err, nil, upstream.Trailer())
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	copyMetadata(stream.ResponseTrailer(), upstream.Trailer())
+	return nil
+}
+
+// outgoing returns ctx carrying the forwarded headers of the request as
+// outgoing metadata. The deadline, set by connect from the request, is
+// carried by ctx itself.
+func outgoing(ctx context.Context, header http.Header) context.Context {
+	md := metadata.MD{}
+	for _, h := range forwardedHeaders {
+		if values := header.Values(h); len(values) > 0 {
+			md.Set(h, values...)
+		}
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// copyMetadata adds the response metadata to h, leaving out what only
+// describes the gRPC transport.
+func copyMetadata(h http.Header, md metadata.MD) {
+	for key, values := range md {
+		if key == "content-type" || strings.HasPrefix(key, "grpc-") || strings.HasPrefix(key, ":") {
+			continue
+		}
+		for _, v := range values {
+			h.Add(key, v)
+		}
+	}
+}
+
+// toConnect converts a gRPC error to a connect error with the same code,
+// message and details, and the response metadata of the call.
+func toConnect(err error, header, trailer metadata.MD) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return connect.NewError(connect.CodeUnknown, err)
+	}
+	cerr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, d := range st.Proto().GetDetails() {
+		msg, err := d.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+		if detail, err := connect.NewErrorDetail(msg); err == nil {
+			cerr.AddDetail(detail)
+		}
+	}
+	copyMetadata(cerr.Meta(), header)
+	copyMetadata(cerr.Meta(), trailer)
+	return cerr
+}
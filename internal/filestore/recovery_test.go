@@ -0,0 +1,265 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	"github.com/sabuhigr/grpc-demo/internal/storetest"
+)
+
+func open(t *testing.T, dir string) *Store {
+	t.Helper()
+	s, err := Open(Options{Dir: dir, Sync: SyncAlways})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return s
+}
+
+// crash stops s like a killed process: no final snapshot, and the log keeps
+// whatever was written to it.
+func crash(t *testing.T, s *Store) {
+	t.Helper()
+	close(s.done)
+	s.wg.Wait()
+	if err := s.wal.file.Close(); err != nil {
+		t.Fatalf("close wal: %v", err)
+	}
+}
+
+// writes creates n news and returns them as stored.
+func writes(t *testing.T, s *Store, n int) []*memstore.News {
+	t.Helper()
+	var created []*memstore.News
+	for range n {
+		created = append(created, storetest.Create(t, s, storetest.NewNews("alice", "go")))
+	}
+	return created
+}
+
+// wantNews fails unless s holds exactly want, deleted or not.
+func wantNews(t *testing.T, s *Store, want ...*memstore.News) {
+	t.Helper()
+	all, err := s.GetAll(context.Background(), true)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(all) != len(want) {
+		t.Fatalf("store holds %d news, want %d", len(all), len(want))
+	}
+	for i := range want {
+		if diff := storetest.Equal(all[i], want[i]); diff != "" {
+			t.Errorf("news %d: %s", i, diff)
+		}
+	}
+}
+
+// lastSegment returns the path of the newest log segment in dir.
+func lastSegment(t *testing.T, dir string) string {
+	t.Helper()
+	starts, err := listSegments(dir)
+	if err != nil || len(starts) == 0 {
+		t.Fatalf("list segments: %v, %d found", err, len(starts))
+	}
+	return filepath.Join(dir, segmentName(starts[len(starts)-1]))
+}
+
+func TestReopenAfterWrites(t *testing.T) {
+	for _, stop := range []struct {
+		name string
+		fn   func(t *testing.T, s *Store)
+	}{
+		{"close", func(t *testing.T, s *Store) {
+			if err := s.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+		}},
+		{"crash", crash},
+	} {
+		t.Run(stop.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			s := open(t, dir)
+			created := writes(t, s, 4)
+			updated, err := s.Update(ctx, &memstore.News{ID: created[0].ID, Title: "new title"}, []string{memstore.FieldTitle})
+			if err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			deleted, err := s.Delete(ctx, created[1].ID)
+			if err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if err := s.Purge(ctx, created[2].ID); err != nil {
+				t.Fatalf("Purge: %v", err)
+			}
+			key, err := s.CreateAPIKey(ctx, &memstore.APIKey{ID: uuid.New(), Name: "ci", Owner: "alice", Hash: []byte("hash")})
+			if err != nil {
+				t.Fatalf("CreateAPIKey: %v", err)
+			}
+			stop.fn(t, s)
+
+			s = open(t, dir)
+			defer s.Close()
+			wantNews(t, s, updated, deleted, created[3])
+			got, err := s.GetAPIKey(ctx, key.ID)
+			if err != nil {
+				t.Fatalf("GetAPIKey: %v", err)
+			}
+			if got.Owner != key.Owner || string(got.Hash) != string(key.Hash) || !got.CreatedAt.Equal(key.CreatedAt) {
+				t.Errorf("API key %+v, want %+v", got, key)
+			}
+
+			// The recovered store keeps writing where it left off.
+			more := writes(t, s, 1)
+			wantNews(t, s, updated, deleted, created[3], more[0])
+		})
+	}
+}
+
+func TestTornTail(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir)
+	created := writes(t, s, 3)
+	crash(t, s)
+
+	// A frame header promising more bytes than were written.
+	f, err := os.OpenFile(lastSegment(t, dir), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{0, 0, 0, 100, 1, 2, 3, 4, '{', '"'}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	s = open(t, dir)
+	wantNews(t, s, created...)
+	// The torn frame is cut, so later writes are not hidden behind it.
+	created = append(created, writes(t, s, 1)...)
+	crash(t, s)
+
+	s = open(t, dir)
+	defer s.Close()
+	wantNews(t, s, created...)
+}
+
+func TestCorruptTail(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir)
+	created := writes(t, s, 3)
+	crash(t, s)
+
+	path := lastSegment(t, dir)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[len(raw)-2] ^= 0xff
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s = open(t, dir)
+	defer s.Close()
+	// Only the damaged last entry is lost.
+	wantNews(t, s, created[:2]...)
+}
+
+func TestCorruptSealedSegment(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir)
+	writes(t, s, 2)
+	sealed := lastSegment(t, dir)
+	if err := s.wal.rotate(s.lsn + 1); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	writes(t, s, 1)
+	crash(t, s)
+
+	raw, err := os.ReadFile(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[len(raw)-2] ^= 0xff
+	if err := os.WriteFile(sealed, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Damage before the end of the log is not a torn write; entries would
+	// be lost silently, so recovery refuses.
+	if s, err := Open(Options{Dir: dir, Sync: SyncAlways}); err == nil {
+		s.Close()
+		t.Fatal("Open succeeded on a corrupt sealed segment")
+	}
+}
+
+func TestCrashDuringCheckpoint(t *testing.T) {
+	for _, step := range []struct {
+		name string
+		fn   func(t *testing.T, s *Store)
+	}{
+		// The snapshot is installed, but the log it covers is neither
+		// rotated nor removed, so replay must skip entries it already holds.
+		{"snapshot written, log not rotated", func(t *testing.T, s *Store) {
+			var err error
+			s.Store.Snapshot(func(all []*memstore.News, keys []*memstore.APIKey) {
+				err = writeSnapshot(s.opts.Dir, s.lsn, all, keys)
+			})
+			if err != nil {
+				t.Fatalf("writeSnapshot: %v", err)
+			}
+		}},
+		// The log is rotated, but the snapshot was never written.
+		{"log rotated, no snapshot", func(t *testing.T, s *Store) {
+			if err := s.wal.rotate(s.lsn + 1); err != nil {
+				t.Fatalf("rotate: %v", err)
+			}
+		}},
+	} {
+		t.Run(step.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := open(t, dir)
+			created := writes(t, s, 3)
+			step.fn(t, s)
+			crash(t, s)
+
+			s = open(t, dir)
+			wantNews(t, s, created...)
+			created = append(created, writes(t, s, 2)...)
+			crash(t, s)
+
+			s = open(t, dir)
+			defer s.Close()
+			wantNews(t, s, created...)
+		})
+	}
+}
+
+func TestCheckpointCompactsLog(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir)
+	created := writes(t, s, 3)
+	if err := s.Checkpoint(); err != nil {
+		t.Fatalf("Checkpoint: %v", err)
+	}
+	starts, err := listSegments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(starts) != 1 || starts[0] != s.lsn+1 {
+		t.Errorf("segments %v after checkpoint at lsn %d, want only %d", starts, s.lsn, s.lsn+1)
+	}
+	crash(t, s)
+
+	s = open(t, dir)
+	defer s.Close()
+	wantNews(t, s, created...)
+	if _, err := os.Stat(filepath.Join(dir, snapshotFile)); errors.Is(err, os.ErrNotExist) {
+		t.Error("no snapshot written")
+	}
+}
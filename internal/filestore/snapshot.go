@@ -0,0 +1,167 @@
+package filestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+)
+
+const snapshotFile = "snapshot.json"
+
+type snapshot struct {
+	// LSN is the last log entry the snapshot includes.
+	LSN     uint64         `json:"lsn"`
+	News    []newsRecord   `json:"news"`
+	APIKeys []apiKeyRecord `json:"api_keys,omitempty"`
+}
+
+// newsRecord is the on-disk form of memstore.News.
+type newsRecord struct {
+	ID        uuid.UUID `json:"id"`
+	Author    string    `json:"author"`
+	Title     string    `json:"title"`
+	Summary   string    `json:"summary"`
+	Content   string    `json:"content"`
+	Source    string    `json:"source"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at,omitzero"`
+}
+
+func toRecord(news *memstore.News) newsRecord {
+	r := newsRecord{
+		ID:        news.ID,
+		Author:    news.Author,
+		Title:     news.Title,
+		Summary:   news.Summary,
+		Content:   news.Content,
+		Tags:      news.Tags,
+		CreatedAt: news.CreatedAt,
+		UpdatedAt: news.UpdatedAt,
+		DeletedAt: news.DeletedAt,
+	}
+	if news.Source != nil {
+		r.Source = news.Source.String()
+	}
+	return r
+}
+
+func (r newsRecord) toNews() (*memstore.News, error) {
+	source, err := url.Parse(r.Source)
+	if err != nil {
+		return nil, fmt.Errorf("decode source of news %s: %w", r.ID, err)
+	}
+
+	return &memstore.News{
+		ID:        r.ID,
+		Author:    r.Author,
+		Title:     r.Title,
+		Summary:   r.Summary,
+		Content:   r.Content,
+		Source:    source,
+		Tags:      r.Tags,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+		DeletedAt: r.DeletedAt,
+	}, nil
+}
+
+// apiKeyRecord is the on-disk form of memstore.APIKey. Byte slices are
+// base64 encoded by encoding/json.
+type apiKeyRecord struct {
+	ID                uuid.UUID `json:"id"`
+	Name              string    `json:"name"`
+	Owner             string    `json:"owner"`
+	Scopes            []string  `json:"scopes"`
+	Hash              []byte    `json:"hash"`
+	PreviousHash      []byte    `json:"previous_hash,omitempty"`
+	PreviousExpiresAt time.Time `json:"previous_expires_at,omitzero"`
+	CreatedAt         time.Time `json:"created_at"`
+	ExpiresAt         time.Time `json:"expires_at,omitzero"`
+	LastUsedAt        time.Time `json:"last_used_at,omitzero"`
+	RevokedAt         time.Time `json:"revoked_at,omitzero"`
+}
+
+func toAPIKeyRecord(key *memstore.APIKey) apiKeyRecord {
+	return apiKeyRecord(*key)
+}
+
+func (r apiKeyRecord) toAPIKey() *memstore.APIKey {
+	key := memstore.APIKey(r)
+	return &key
+}
+
+// readSnapshot returns the changes that rebuild the snapshot in dir, or
+// nothing if there is none yet.
+func readSnapshot(dir string) (uint64, []memstore.Change, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("read snapshot: %w", err)
+	}
+
+	snap := &snapshot{}
+	if err := json.Unmarshal(raw, snap); err != nil {
+		return 0, nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	changes := make([]memstore.Change, 0, len(snap.News)+len(snap.APIKeys))
+	for _, record := range snap.News {
+		n, err := record.toNews()
+		if err != nil {
+			return 0, nil, err
+		}
+		changes = append(changes, memstore.Change{Type: memstore.EventCreated, News: n})
+	}
+	for _, record := range snap.APIKeys {
+		changes = append(changes, memstore.Change{Type: memstore.EventCreated, APIKey: record.toAPIKey()})
+	}
+	return snap.LSN, changes, nil
+}
+
+// writeSnapshot replaces the snapshot in dir atomically: it is written to a
+// temporary file, synced, then renamed over the old one.
+func writeSnapshot(dir string, lsn uint64, news []*memstore.News, apiKeys []*memstore.APIKey) error {
+	snap := snapshot{LSN: lsn, News: make([]newsRecord, 0, len(news))}
+	for _, n := range news {
+		snap.News = append(snap.News, toRecord(n))
+	}
+	for _, key := range apiKeys {
+		snap.APIKeys = append(snap.APIKeys, toAPIKeyRecord(key))
+	}
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	tmp := filepath.Join(dir, snapshotFile+".tmp")
+	f, err := os.OpenFile(filepath.Clean(tmp), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	if _, err := f.Write(raw); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("sync snapshot: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, snapshotFile)); err != nil {
+		return fmt.Errorf("install snapshot: %w", err)
+	}
+	return syncDir(dir)
+}
@@ -0,0 +1,327 @@
+// Package filestore is a durable NewsStorer. It keeps the working set in a
+// memstore.Store and writes every change ahead to an append-only log before
+// the change becomes visible. Periodic snapshots keep the log short.
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	log "github.com/sirupsen/logrus"
+)
+
+// SyncPolicy decides when log writes are flushed to disk.
+type SyncPolicy int
+
+const (
+	// SyncAlways fsyncs every write before it is acknowledged.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval fsyncs in the background every Options.SyncInterval. A
+	// crash may lose the writes of the last interval.
+	SyncInterval
+	// SyncNever leaves flushing to the operating system.
+	SyncNever
+)
+
+// ParseSyncPolicy parses "always", "interval" or "never".
+func ParseSyncPolicy(s string) (SyncPolicy, error) {
+	switch s {
+	case "always":
+		return SyncAlways, nil
+	case "interval":
+		return SyncInterval, nil
+	case "never":
+		return SyncNever, nil
+	default:
+		return 0, fmt.Errorf("unknown fsync policy %q, want always, interval or never", s)
+	}
+}
+
+func (p SyncPolicy) String() string {
+	switch p {
+	case SyncAlways:
+		return "always"
+	case SyncInterval:
+		return "interval"
+	case SyncNever:
+		return "never"
+	default:
+		return fmt.Sprintf("SyncPolicy(%d)", int(p))
+	}
+}
+
+// Options configures a Store.
+type Options struct {
+	// Dir holds the log segments and the snapshot. It is created if missing
+	// and must not be shared with another Store.
+	Dir  string
+	Sync SyncPolicy
+	// SyncInterval is the flush period under SyncInterval. Defaults to 1s.
+	SyncInterval time.Duration
+	// SnapshotInterval is how often the state is snapshotted and the log
+	// compacted. Defaults to 5m.
+	SnapshotInterval time.Duration
+}
+
+// Store is a memstore.Store whose changes survive restarts. Reads and
+// watches are served from memory.
+type Store struct {
+	*memstore.Store
+
+	opts Options
+	wal  *wal
+	// lsn is the last log sequence number written. It only changes inside
+	// the commit hook, so the memstore lock guards it.
+	lsn uint64
+
+	snapMu      sync.Mutex
+	snapshotLSN uint64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Open recovers the store in opts.Dir and starts its background work. A
+// torn write at the end of the log, left by a crash, is discarded.
+func Open(opts Options) (*Store, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("filestore: data dir is required")
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = time.Second
+	}
+	if opts.SnapshotInterval <= 0 {
+		opts.SnapshotInterval = 5 * time.Minute
+	}
+	if err := os.MkdirAll(opts.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	s := &Store{
+		opts: opts,
+		done: make(chan struct{}),
+	}
+	s.Store = memstore.New(memstore.WithCommitHook(s.append))
+
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.background()
+	return s, nil
+}
+
+// replay loads the snapshot and replays the log written after it.
+func (s *Store) replay() error {
+	snapshotLSN, changes, err := readSnapshot(s.opts.Dir)
+	if err != nil {
+		return err
+	}
+	s.Store.Load(changes...)
+	s.lsn, s.snapshotLSN = snapshotLSN, snapshotLSN
+
+	starts, err := listSegments(s.opts.Dir)
+	if err != nil {
+		return err
+	}
+
+	tailStart, tailSize := snapshotLSN+1, int64(0)
+	for i, start := range starts {
+		payloads, size, err := readSegment(filepath.Join(s.opts.Dir, segmentName(start)))
+		last := i == len(starts)-1
+		switch {
+		case errors.Is(err, errCorruptFrame) && last:
+			log.WithField("segment", segmentName(start)).Warn("Discarding torn write at the end of the wal")
+		case err != nil:
+			return fmt.Errorf("replay %s: %w", segmentName(start), err)
+		}
+
+		for _, payload := range payloads {
+			entry, changes, err := decodeEntry(payload)
+			if err != nil {
+				return fmt.Errorf("replay %s: %w", segmentName(start), err)
+			}
+			if entry.LSN <= s.lsn {
+				continue
+			}
+			if entry.LSN != s.lsn+1 {
+				return fmt.Errorf("replay %s: wal jumps from lsn %d to %d", segmentName(start), s.lsn, entry.LSN)
+			}
+			s.Store.Load(changes...)
+			s.lsn = entry.LSN
+		}
+
+		if last {
+			tailStart, tailSize = start, size
+		}
+	}
+
+	s.wal, err = openWAL(s.opts.Dir, s.opts.Sync, tailStart, tailSize)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"dir":      s.opts.Dir,
+		"lsn":      s.lsn,
+		"snapshot": snapshotLSN,
+	}).Info("Recovered file store")
+	return nil
+}
+
+// append is the memstore commit hook. It writes the changes as one log
+// entry, so a batch is replayed entirely or not at all.
+func (s *Store) append(ctx context.Context, changes ...memstore.Change) error {
+	// Nothing is written yet, so a caller that went away can still back out.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	payload, err := encodeEntry(s.lsn+1, changes)
+	if err != nil {
+		return err
+	}
+	if err := s.wal.append(payload); err != nil {
+		log.WithError(err).Error("Failed to write ahead to the wal")
+		return err
+	}
+	s.lsn++
+	return nil
+}
+
+func (s *Store) background() {
+	defer s.wg.Done()
+
+	syncTicker := time.NewTicker(s.opts.SyncInterval)
+	defer syncTicker.Stop()
+	snapshotTicker := time.NewTicker(s.opts.SnapshotInterval)
+	defer snapshotTicker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-syncTicker.C:
+			if s.opts.Sync != SyncInterval {
+				continue
+			}
+			if err := s.wal.sync(); err != nil {
+				log.WithError(err).Error("Failed to sync the wal")
+			}
+		case <-snapshotTicker.C:
+			if err := s.Checkpoint(); err != nil {
+				log.WithError(err).Error("Failed to snapshot the file store")
+			}
+		}
+	}
+}
+
+// Checkpoint snapshots the current state and drops the log it covers.
+func (s *Store) Checkpoint() error {
+	s.snapMu.Lock()
+	defer s.snapMu.Unlock()
+
+	var (
+		state     []*memstore.News
+		apiKeys   []*memstore.APIKey
+		lsn       uint64
+		rotateErr error
+	)
+	s.Store.Snapshot(func(all []*memstore.News, keys []*memstore.APIKey) {
+		state, apiKeys, lsn = all, keys, s.lsn
+		if lsn != s.snapshotLSN {
+			// Writers are blocked, so everything after lsn lands in the
+			// new segment.
+			rotateErr = s.wal.rotate(lsn + 1)
+		}
+	})
+	if lsn == s.snapshotLSN {
+		return nil
+	}
+	if rotateErr != nil {
+		return rotateErr
+	}
+
+	if err := writeSnapshot(s.opts.Dir, lsn, state, apiKeys); err != nil {
+		return err
+	}
+	s.snapshotLSN = lsn
+
+	if err := s.wal.removeBefore(lsn + 1); err != nil {
+		return err
+	}
+	return syncDir(s.opts.Dir)
+}
+
+// Close stops background work, writes a final snapshot and closes the log.
+func (s *Store) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	return errors.Join(s.Checkpoint(), s.wal.close())
+}
+
+// entry is one log record: the changes committed together under one LSN.
+type entry struct {
+	LSN     uint64         `json:"lsn"`
+	Changes []changeRecord `json:"changes"`
+}
+
+// changeRecord carries either News or an APIKey, like memstore.Change.
+type changeRecord struct {
+	Type   memstore.EventType `json:"type"`
+	News   *newsRecord        `json:"news,omitempty"`
+	APIKey *apiKeyRecord      `json:"api_key,omitempty"`
+}
+
+func encodeEntry(lsn uint64, changes []memstore.Change) ([]byte, error) {
+	e := entry{LSN: lsn, Changes: make([]changeRecord, 0, len(changes))}
+	for _, change := range changes {
+		record := changeRecord{Type: change.Type}
+		if change.APIKey != nil {
+			key := toAPIKeyRecord(change.APIKey)
+			record.APIKey = &key
+		} else {
+			news := toRecord(change.News)
+			record.News = &news
+		}
+		e.Changes = append(e.Changes, record)
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("encode wal entry: %w", err)
+	}
+	return payload, nil
+}
+
+func decodeEntry(payload []byte) (*entry, []memstore.Change, error) {
+	e := &entry{}
+	if err := json.Unmarshal(payload, e); err != nil {
+		return nil, nil, fmt.Errorf("decode wal entry: %w", err)
+	}
+
+	changes := make([]memstore.Change, 0, len(e.Changes))
+	for _, record := range e.Changes {
+		switch {
+		case record.APIKey != nil:
+			changes = append(changes, memstore.Change{Type: record.Type, APIKey: record.APIKey.toAPIKey()})
+		case record.News != nil:
+			news, err := record.News.toNews()
+			if err != nil {
+				return nil, nil, err
+			}
+			changes = append(changes, memstore.Change{Type: record.Type, News: news})
+		default:
+			return nil, nil, fmt.Errorf("decode wal entry %d: empty change", e.LSN)
+		}
+	}
+	return e, changes, nil
+}
@@ -0,0 +1,24 @@
+package filestore_test
+
+import (
+	"testing"
+
+	"github.com/sabuhigr/grpc-demo/internal/filestore"
+	ingrpc "github.com/sabuhigr/grpc-demo/internal/grpc"
+	"github.com/sabuhigr/grpc-demo/internal/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) ingrpc.NewsStorer {
+		s, err := filestore.Open(filestore.Options{Dir: t.TempDir(), Sync: filestore.SyncAlways})
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		t.Cleanup(func() {
+			if err := s.Close(); err != nil {
+				t.Errorf("Close: %v", err)
+			}
+		})
+		return s
+	})
+}
@@ -0,0 +1,268 @@
+package filestore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentPrefix = "wal-"
+	segmentSuffix = ".log"
+	// frameHeader is the payload length and its CRC-32C, both big endian.
+	frameHeader = 8
+	// maxFrame guards recovery against reading a garbage length.
+	maxFrame = 64 << 20
+)
+
+var (
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+	errCorruptFrame = errors.New("corrupt wal frame")
+	errWALBroken    = errors.New("wal is unusable after a failed write")
+)
+
+// wal is the append-only log, split into segments named after the first LSN
+// they may contain. Only the newest segment is written to.
+type wal struct {
+	dir    string
+	policy SyncPolicy
+
+	mu     sync.Mutex
+	file   *os.File
+	start  uint64
+	size   int64
+	dirty  bool
+	broken bool
+}
+
+func segmentName(start uint64) string {
+	return fmt.Sprintf("%s%020d%s", segmentPrefix, start, segmentSuffix)
+}
+
+// listSegments returns the start LSNs of the segments in dir, ascending.
+func listSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("list wal segments: %w", err)
+	}
+
+	var starts []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		start, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		starts = append(starts, start)
+	}
+	slices.Sort(starts)
+	return starts, nil
+}
+
+// readSegment returns the payloads of the intact frames in the segment and
+// the offset right after the last one. errCorruptFrame is returned alongside
+// them when the segment ends in a torn or damaged frame.
+func readSegment(path string) ([][]byte, int64, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, 0, fmt.Errorf("open wal segment: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		payloads [][]byte
+		offset   int64
+		header   [frameHeader]byte
+	)
+	for {
+		if _, err := io.ReadFull(f, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return payloads, offset, nil
+			}
+			return payloads, offset, errCorruptFrame
+		}
+
+		length := binary.BigEndian.Uint32(header[:4])
+		if length > maxFrame {
+			return payloads, offset, errCorruptFrame
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(f, payload); err != nil {
+			return payloads, offset, errCorruptFrame
+		}
+		if crc32.Checksum(payload, castagnoli) != binary.BigEndian.Uint32(header[4:]) {
+			return payloads, offset, errCorruptFrame
+		}
+
+		payloads = append(payloads, payload)
+		offset += frameHeader + int64(length)
+	}
+}
+
+// openWAL opens the segment starting at start for appending, creating it if
+// needed. size is the length of its intact prefix; anything after it is cut.
+func openWAL(dir string, policy SyncPolicy, start uint64, size int64) (*wal, error) {
+	w := &wal{dir: dir, policy: policy}
+	if err := w.open(start, size); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *wal) open(start uint64, size int64) error {
+	path := filepath.Join(w.dir, segmentName(start))
+	f, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open wal segment: %w", err)
+	}
+	if err := f.Truncate(size); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("truncate wal segment: %w", err)
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("seek wal segment: %w", err)
+	}
+	if err := syncDir(w.dir); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	w.file, w.start, w.size = f, start, size
+	return nil
+}
+
+// append writes one frame, syncing it right away under SyncAlways.
+func (w *wal) append(payload []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.broken {
+		return errWALBroken
+	}
+
+	frame := make([]byte, frameHeader, frameHeader+len(payload))
+	binary.BigEndian.PutUint32(frame[:4], uint32(len(payload))) //nolint:gosec // payloads are far below 4GiB
+	binary.BigEndian.PutUint32(frame[4:], crc32.Checksum(payload, castagnoli))
+	frame = append(frame, payload...)
+
+	if _, err := w.file.Write(frame); err != nil {
+		w.rollback()
+		return fmt.Errorf("write wal: %w", err)
+	}
+	if w.policy == SyncAlways {
+		if err := w.file.Sync(); err != nil {
+			w.rollback()
+			return fmt.Errorf("sync wal: %w", err)
+		}
+	}
+
+	w.size += int64(len(frame))
+	w.dirty = true
+	return nil
+}
+
+// rollback cuts a partially written frame, so later frames are not hidden
+// behind it on recovery. Callers must hold mu.
+func (w *wal) rollback() {
+	if err := w.file.Truncate(w.size); err != nil {
+		w.broken = true
+		return
+	}
+	if _, err := w.file.Seek(w.size, io.SeekStart); err != nil {
+		w.broken = true
+	}
+}
+
+// sync flushes written frames to disk if there are any.
+func (w *wal) sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.dirty || w.policy == SyncNever {
+		return nil
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("sync wal: %w", err)
+	}
+	w.dirty = false
+	return nil
+}
+
+// rotate seals the current segment and starts a new one at start.
+func (w *wal) rotate(start uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.start == start {
+		return nil
+	}
+
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("sync wal: %w", err)
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close wal segment: %w", err)
+	}
+	w.dirty = false
+	if err := w.open(start, 0); err != nil {
+		w.broken = true
+		return err
+	}
+	return nil
+}
+
+// removeBefore deletes the sealed segments that start before start.
+func (w *wal) removeBefore(start uint64) error {
+	starts, err := listSegments(w.dir)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, s := range starts {
+		if s < start {
+			if err := os.Remove(filepath.Join(w.dir, segmentName(s))); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("remove wal segment: %w", err))
+			}
+		}
+	}
+	return errs
+}
+
+func (w *wal) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.policy != SyncNever {
+		if err := w.file.Sync(); err != nil {
+			_ = w.file.Close()
+			return fmt.Errorf("sync wal: %w", err)
+		}
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close wal segment: %w", err)
+	}
+	return nil
+}
+
+// syncDir makes created, renamed and removed files in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(filepath.Clean(dir))
+	if err != nil {
+		return fmt.Errorf("open data dir: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync data dir: %w", err)
+	}
+	return nil
+}
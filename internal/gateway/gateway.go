@@ -0,0 +1,106 @@
+// Package gateway serves NewsService as JSON over HTTP, following the
+// google.api.http annotations of the proto. Calls are transcoded to gRPC and
+// sent to the server, so they pass the same interceptors as native calls.
+// Errors are written as google.rpc.Status JSON bodies with their details and
+// the HTTP status matching the gRPC code.
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/internal/idempotency"
+	"github.com/sabuhigr/grpc-demo/internal/logging"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NDJSONContentType is the content type of streamed responses: one JSON
+// object per line, {"result": ...} for messages and {"error": ...} if the
+// stream fails.
+const NDJSONContentType = "application/x-ndjson"
+
+// forwardedHeaders are passed to the server as metadata of the same name.
+var forwardedHeaders = []string{logging.RequestIDHeader, idempotency.KeyHeader}
+
+// NewHandler returns the HTTP handler of the gateway, calling the server
+// over conn.
+func NewHandler(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &marshaler{JSONPb: &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{EmitUnpopulated: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}}),
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+		runtime.WithErrorHandler(errorHandler),
+	)
+	if err := newsv1.RegisterNewsServiceHandler(ctx, mux, conn); err != nil {
+		return nil, fmt.Errorf("register NewsService: %w", err)
+	}
+	return mux, nil
+}
+
+// marshaler is the JSON marshaler, announcing streams as NDJSON.
+type marshaler struct {
+	*runtime.JSONPb
+}
+
+func (m *marshaler) StreamContentType(any) string {
+	return NDJSONContentType
+}
+
+// incomingHeader forwards the request id and idempotency key along with the
+// headers forwarded by default. Authorization is always forwarded as is, so
+// it and Cookie are not copied to grpcgateway-* metadata, which is logged.
+// PeerHeader is only set by the gateway, so clients may not send it.
+func incomingHeader(key string) (string, bool) {
+	lower := strings.ToLower(key)
+	if lower == "authorization" || lower == "cookie" {
+		return "", false
+	}
+	for _, h := range forwardedHeaders {
+		if lower == h {
+			return h, true
+		}
+	}
+	name, ok := runtime.DefaultHeaderMatcher(key)
+	if strings.ToLower(name) == PeerHeader {
+		return "", false
+	}
+	return name, ok
+}
+
+// outgoingHeader returns the request id and idempotency headers as they
+// were sent, and other response metadata as Grpc-Metadata-* headers.
+func outgoingHeader(key string) (string, bool) {
+	switch key {
+	case logging.RequestIDHeader, idempotency.ReplayedHeader:
+		return http.CanonicalHeaderKey(key), true
+	case "content-type":
+		// The gRPC content type says nothing about the HTTP response.
+		return "", false
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// errorHandler adds a Retry-After header for errors carrying RetryInfo,
+// e.g. rate limit rejections, to the default error response.
+func errorHandler(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			// Round up, so clients never retry too early.
+			seconds := math.Ceil(info.GetRetryDelay().AsDuration().Seconds())
+			w.Header().Set("Retry-After", strconv.FormatFloat(seconds, 'f', 0, 64))
+		}
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, m, w, r, err)
+}
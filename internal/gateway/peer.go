@@ -0,0 +1,146 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// PeerHeader is the metadata key under which calls from HTTP clients carry
+// the ID of their peer to the in-process server. Only the gateway's own
+// connection sets it; it is never taken from a request.
+const PeerHeader = "x-gateway-peer"
+
+// Peers hands the address and TLS state of HTTP clients to the in-process
+// gRPC server, which otherwise sees every call coming from the in-process
+// connection. Rate limits, logs and client certificate authentication then
+// apply to the HTTP client. The peer itself never leaves the process; calls
+// only carry a random ID under PeerHeader.
+type Peers struct {
+	mu   sync.Mutex
+	byID map[string]*peer.Peer
+}
+
+// NewPeers returns an empty Peers.
+func NewPeers() *Peers {
+	return &Peers{byID: make(map[string]*peer.Peer)}
+}
+
+// Handler records the remote address and TLS state of each request in its
+// context for the client interceptors.
+func (p *Peers) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, err := netip.ParseAddrPort(r.RemoteAddr)
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		client := &peer.Peer{Addr: net.TCPAddrFromAddrPort(addr)}
+		if r.TLS != nil {
+			client.AuthInfo = credentials.TLSInfo{
+				State:          *r.TLS,
+				CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+			}
+		}
+		h.ServeHTTP(w, r.WithContext(peer.NewContext(r.Context(), client)))
+	})
+}
+
+// UnaryClientInterceptor passes the peer recorded by Handler to the server.
+func (p *Peers) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, release := p.register(ctx)
+		defer release()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor passes the peer recorded by Handler to the
+// server, until ctx is done.
+func (p *Peers) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, release := p.register(ctx)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		context.AfterFunc(ctx, release)
+		return stream, nil
+	}
+}
+
+// UnaryServerInterceptor makes the peer passed by the client interceptors
+// the peer of the call. It must run before the interceptors reading it.
+func (p *Peers) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(p.withPeer(ctx), req)
+	}
+}
+
+// StreamServerInterceptor makes the peer passed by the client interceptors
+// the peer of the stream. It must run before the interceptors reading it.
+func (p *Peers) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: p.withPeer(ss.Context())})
+	}
+}
+
+// register stores the peer of ctx under a new ID and returns ctx carrying
+// the ID, replacing any sent by the client, and a func removing the peer.
+func (p *Peers) register(ctx context.Context) (context.Context, func()) {
+	client, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx, func() {}
+	}
+	id := rand.Text()
+	p.mu.Lock()
+	p.byID[id] = client
+	p.mu.Unlock()
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set(PeerHeader, id)
+	return metadata.NewOutgoingContext(ctx, md), func() {
+		p.mu.Lock()
+		delete(p.byID, id)
+		p.mu.Unlock()
+	}
+}
+
+// withPeer returns ctx with the peer registered under the call's ID, which
+// is dropped from the metadata. Calls without a known ID keep the peer of
+// the in-process connection.
+func (p *Peers) withPeer(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ids := md.Get(PeerHeader)
+	if len(ids) != 1 {
+		return ctx
+	}
+	p.mu.Lock()
+	client, ok := p.byID[ids[0]]
+	p.mu.Unlock()
+	if !ok {
+		return ctx
+	}
+	md = md.Copy()
+	delete(md, PeerHeader)
+	return peer.NewContext(metadata.NewIncomingContext(ctx, md), client)
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,214 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/internal/apikey"
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	"github.com/sabuhigr/grpc-demo/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxAPIKeyNameLength = 100
+
+// APIKeyStorer persists API keys, reporting failures like NewsStorer.
+type APIKeyStorer interface {
+	CreateAPIKey(ctx context.Context, key *memstore.APIKey) (*memstore.APIKey, error)
+	GetAPIKey(ctx context.Context, id uuid.UUID) (*memstore.APIKey, error)
+	ListAPIKeys(ctx context.Context, owner string, includeRevoked bool) ([]*memstore.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (*memstore.APIKey, error)
+	RotateAPIKey(ctx context.Context, id uuid.UUID, hash []byte, grace time.Duration) (*memstore.APIKey, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// APIKeyServer implements ApiKeyService.
+type APIKeyServer struct {
+	newsv1.UnimplementedApiKeyServiceServer
+	store APIKeyStorer
+}
+
+// NewAPIKeyServer creates a new ApiKeyService server as pointer.
+func NewAPIKeyServer(store APIKeyStorer) *APIKeyServer {
+	return &APIKeyServer{
+		store: store,
+	}
+}
+
+func (s *APIKeyServer) CreateApiKey(ctx context.Context, in *newsv1.CreateApiKeyRequest) (*newsv1.CreateApiKeyResponse, error) {
+	if in.Name == "" || len(in.Name) > maxAPIKeyNameLength {
+		return nil, errorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: fmt.Sprintf("name must be 1 to %d characters", maxAPIKeyNameLength), Type: "invalid_name", Description: "invalid name"})
+	}
+	for _, scope := range in.Scopes {
+		if scope == "" {
+			return nil, errorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: "scopes must not be empty", Type: "invalid_scope", Description: "invalid scope"})
+		}
+	}
+	if in.Ttl != nil && (in.Ttl.CheckValid() != nil || in.Ttl.AsDuration() <= 0) {
+		return nil, errorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: "ttl must be positive", Type: "invalid_ttl", Description: "invalid ttl"})
+	}
+
+	principal, admin, err := keyCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner := in.Owner
+	if owner == "" {
+		owner = principal.Subject
+	}
+	if owner != principal.Subject && !admin {
+		return nil, notKeyOwner("only admins may create API keys for " + owner)
+	}
+	// A key never grants more than its creator holds.
+	for _, scope := range in.Scopes {
+		if !admin && !principal.HasRole(scope) {
+			return nil, errorWithDetails(codes.PermissionDenied, types.ErrDetails{Code: 403, Message: fmt.Sprintf("scope %q is not held by the caller", scope), Type: "scope_not_held", Description: "scope not held"})
+		}
+	}
+
+	id := uuid.New()
+	secret, hash, err := apikey.NewSecret(id)
+	if err != nil {
+		return nil, err
+	}
+	key := &memstore.APIKey{
+		ID:     id,
+		Name:   in.Name,
+		Owner:  owner,
+		Scopes: in.Scopes,
+		Hash:   hash,
+	}
+	if in.Ttl != nil {
+		key.ExpiresAt = time.Now().UTC().Add(in.Ttl.AsDuration())
+	}
+
+	created, err := s.store.CreateAPIKey(ctx, key)
+	if err != nil {
+		return nil, storeStatus(apiKeyResourceType, err, id.String()).Err()
+	}
+	return &newsv1.CreateApiKeyResponse{ApiKey: toAPIKey(created), Secret: secret}, nil
+}
+
+func (s *APIKeyServer) ListApiKeys(ctx context.Context, in *newsv1.ListApiKeysRequest) (*newsv1.ListApiKeysResponse, error) {
+	principal, admin, err := keyCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner := in.Owner
+	if !admin {
+		if owner == "" {
+			owner = principal.Subject
+		}
+		if owner != principal.Subject {
+			return nil, notKeyOwner("only admins may list the API keys of " + owner)
+		}
+	}
+	keys, err := s.store.ListAPIKeys(ctx, owner, in.IncludeRevoked)
+	if err != nil {
+		return nil, storeStatus(apiKeyResourceType, err, "").Err()
+	}
+
+	resp := &newsv1.ListApiKeysResponse{ApiKeys: make([]*newsv1.ApiKey, 0, len(keys))}
+	for _, key := range keys {
+		resp.ApiKeys = append(resp.ApiKeys, toAPIKey(key))
+	}
+	return resp, nil
+}
+
+func (s *APIKeyServer) RevokeApiKey(ctx context.Context, in *newsv1.RevokeApiKeyRequest) (*newsv1.RevokeApiKeyResponse, error) {
+	id, err := uuid.Parse(in.Id)
+	if err != nil {
+		return nil, errorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_UUID", Description: "invalid UUID"})
+	}
+	if err := s.checkKeyOwner(ctx, id); err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.store.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return nil, storeStatus(apiKeyResourceType, err, in.Id).Err()
+	}
+	return &newsv1.RevokeApiKeyResponse{ApiKey: toAPIKey(revoked)}, nil
+}
+
+func (s *APIKeyServer) RotateApiKey(ctx context.Context, in *newsv1.RotateApiKeyRequest) (*newsv1.RotateApiKeyResponse, error) {
+	id, err := uuid.Parse(in.Id)
+	if err != nil {
+		return nil, errorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_UUID", Description: "invalid UUID"})
+	}
+	if in.GracePeriod != nil && (in.GracePeriod.CheckValid() != nil || in.GracePeriod.AsDuration() < 0) {
+		return nil, errorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: "grace_period must not be negative", Type: "invalid_grace_period", Description: "invalid grace period"})
+	}
+
+	if err := s.checkKeyOwner(ctx, id); err != nil {
+		return nil, err
+	}
+
+	secret, hash, err := apikey.NewSecret(id)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.store.RotateAPIKey(ctx, id, hash, in.GracePeriod.AsDuration())
+	if err != nil {
+		return nil, storeStatus(apiKeyResourceType, err, in.Id).Err()
+	}
+	return &newsv1.RotateApiKeyResponse{ApiKey: toAPIKey(rotated), Secret: secret}, nil
+}
+
+// keyCaller returns the principal of the call, and whether it is an admin,
+// who may manage the keys of every owner.
+func keyCaller(ctx context.Context) (*auth.Principal, bool, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, false, status.Error(codes.Unauthenticated, "missing or unrecognized credentials")
+	}
+	return principal, principal.HasRole(auth.AdminRole), nil
+}
+
+// checkKeyOwner makes sure the caller owns the key with the id, or is an
+// admin.
+func (s *APIKeyServer) checkKeyOwner(ctx context.Context, id uuid.UUID) error {
+	principal, admin, err := keyCaller(ctx)
+	if err != nil || admin {
+		return err
+	}
+	key, err := s.store.GetAPIKey(ctx, id)
+	if err != nil {
+		return storeStatus(apiKeyResourceType, err, id.String()).Err()
+	}
+	if key.Owner != principal.Subject {
+		return notKeyOwner("API key " + id.String() + " is not owned by " + principal.Subject)
+	}
+	return nil
+}
+
+func notKeyOwner(message string) error {
+	return errorWithDetails(codes.PermissionDenied, types.ErrDetails{Code: 403, Message: message, Type: "not_owner", Description: "not the key owner"})
+}
+
+func toAPIKey(key *memstore.APIKey) *newsv1.ApiKey {
+	return &newsv1.ApiKey{
+		Id:         key.ID.String(),
+		Name:       key.Name,
+		Owner:      key.Owner,
+		Scopes:     key.Scopes,
+		CreatedAt:  timestamppb.New(key.CreatedAt),
+		ExpiresAt:  optionalTimestamp(key.ExpiresAt),
+		LastUsedAt: optionalTimestamp(key.LastUsedAt),
+		RevokedAt:  optionalTimestamp(key.RevokedAt),
+	}
+}
+
+// optionalTimestamp leaves zero times unset.
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
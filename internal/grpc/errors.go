@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const (
+	// newsResourceType and apiKeyResourceType name resources in
+	// ResourceInfo error details.
+	newsResourceType   = "news.v1.News"
+	apiKeyResourceType = "news.v1.ApiKey"
+	errorDomain        = "news.v1"
+)
+
+// storeError maps a NewsStorer error to a gRPC error with error details.
+// id names the news the call was about, if any.
+func (s *Server) storeError(err error, id string) error {
+	return s.storeStatus(err, id).Err()
+}
+
+// storeStatus is storeError as a status, for reporting per-item failures.
+func (s *Server) storeStatus(err error, id string) *status.Status {
+	return storeStatus(newsResourceType, err, id)
+}
+
+// storeStatus maps a store error about the resource of the given type and id
+// to a status with error details.
+func storeStatus(resourceType string, err error, id string) *status.Status {
+	var (
+		code   codes.Code
+		detail protoadapt.MessageV1
+	)
+	switch {
+	case errors.Is(err, memstore.ErrNotFound):
+		code = codes.NotFound
+		detail = &errdetails.ResourceInfo{ResourceType: resourceType, ResourceName: id, Description: err.Error()}
+	case errors.Is(err, memstore.ErrAlreadyExists):
+		code = codes.AlreadyExists
+		detail = &errdetails.ResourceInfo{ResourceType: resourceType, ResourceName: id, Description: err.Error()}
+	case errors.Is(err, memstore.ErrBatchAborted):
+		return status.New(codes.Aborted, err.Error())
+	case errors.Is(err, memstore.ErrConflict):
+		code = codes.FailedPrecondition
+		detail = &errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{
+			{Type: "STATE", Subject: id, Description: err.Error()},
+		}}
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	default:
+		// Do not leak backend internals to clients.
+		log.WithError(err).Error("Store failure")
+		code = codes.Internal
+		detail = &errdetails.ErrorInfo{Reason: "STORE_FAILURE", Domain: errorDomain}
+		err = errors.New("internal store failure")
+	}
+
+	st := status.New(code, err.Error())
+	if withDetail, detailErr := st.WithDetails(detail); detailErr == nil {
+		return withDetail
+	}
+	return st
+}
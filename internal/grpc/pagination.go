@@ -0,0 +1,153 @@
+package grpc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// pageToken is the decoded form of ListNewsResponse.next_page_token.
+type pageToken struct {
+	Time   time.Time `json:"t"`
+	Title  string    `json:"ti,omitempty"`
+	ID     uuid.UUID `json:"id"`
+	Filter string    `json:"f"`
+}
+
+// toQuery converts a ListNewsRequest into a store query, resuming from the
+// page token when present.
+func toQuery(in *newsv1.ListNewsRequest) (memstore.Query, error) {
+	q := memstore.Query{
+		Author:         in.Author,
+		TagsAny:        in.TagsAny,
+		TagsAll:        in.TagsAll,
+		SourceHost:     in.SourceHost,
+		IncludeDeleted: in.IncludeDeleted,
+		Limit:          defaultPageSize,
+	}
+
+	var errs error
+	switch {
+	case in.PageSize < 0:
+		errs = errors.Join(errs, errors.New("page_size cannot be negative"))
+	case in.PageSize > maxPageSize:
+		q.Limit = maxPageSize
+	case in.PageSize > 0:
+		q.Limit = int(in.PageSize)
+	}
+
+	if in.CreatedStartTime != nil {
+		q.CreatedStart = in.CreatedStartTime.AsTime()
+	}
+	if in.CreatedEndTime != nil {
+		q.CreatedEnd = in.CreatedEndTime.AsTime()
+	}
+
+	order, desc, err := parseOrderBy(in.OrderBy)
+	if err != nil {
+		errs = errors.Join(errs, err)
+	}
+	q.OrderBy, q.Descending = order, desc
+
+	if in.PageToken != "" {
+		token, err := decodePageToken(in.PageToken)
+		switch {
+		case err != nil:
+			errs = errors.Join(errs, err)
+		case token.Filter != filterFingerprint(in):
+			errs = errors.Join(errs, errors.New("page_token does not match the request filters"))
+		default:
+			q.After = &memstore.Cursor{Time: token.Time, Title: token.Title, ID: token.ID}
+		}
+	}
+
+	return q, errs
+}
+
+func parseOrderBy(orderBy string) (memstore.OrderBy, bool, error) {
+	fields := strings.Fields(orderBy)
+	if len(fields) == 0 {
+		return memstore.OrderByCreatedAt, false, nil
+	}
+
+	desc := false
+	if len(fields) == 2 {
+		switch strings.ToLower(fields[1]) {
+		case "asc":
+		case "desc":
+			desc = true
+		default:
+			return 0, false, fmt.Errorf("order_by direction %q must be asc or desc", fields[1])
+		}
+	} else if len(fields) > 2 {
+		return 0, false, fmt.Errorf("order_by %q must be a field optionally followed by asc or desc", orderBy)
+	}
+
+	switch fields[0] {
+	case "created_at":
+		return memstore.OrderByCreatedAt, desc, nil
+	case "updated_at":
+		return memstore.OrderByUpdatedAt, desc, nil
+	case "title":
+		return memstore.OrderByTitle, desc, nil
+	default:
+		return 0, false, fmt.Errorf("order_by field %q must be created_at, updated_at or title", fields[0])
+	}
+}
+
+// nextPageToken encodes the position of the last news on the page.
+func nextPageToken(in *newsv1.ListNewsRequest, order memstore.OrderBy, last *memstore.News) string {
+	cursor := memstore.CursorFor(last, order)
+	raw, err := json.Marshal(pageToken{
+		Time:   cursor.Time,
+		Title:  cursor.Title,
+		ID:     cursor.ID,
+		Filter: filterFingerprint(in),
+	})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodePageToken(s string) (*pageToken, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("page_token is malformed")
+	}
+
+	token := &pageToken{}
+	if err := json.Unmarshal(raw, token); err != nil {
+		return nil, errors.New("page_token is malformed")
+	}
+	return token, nil
+}
+
+// filterFingerprint hashes everything in the request except the paging
+// fields, so a token cannot be replayed against a different listing.
+func filterFingerprint(in *newsv1.ListNewsRequest) string {
+	filters, _ := proto.Clone(in).(*newsv1.ListNewsRequest)
+	filters.PageSize = 0
+	filters.PageToken = ""
+
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(filters)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
@@ -0,0 +1,145 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPageSize(t *testing.T) {
+	tests := []struct {
+		pageSize  int32
+		wantLimit int
+		wantErr   bool
+	}{
+		{0, defaultPageSize, false},
+		{10, 10, false},
+		{maxPageSize, maxPageSize, false},
+		{maxPageSize + 1, maxPageSize, false},
+		{-1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.pageSize), func(t *testing.T) {
+			q, err := toQuery(&newsv1.ListNewsRequest{PageSize: tt.pageSize})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toQuery: %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && q.Limit != tt.wantLimit {
+				t.Errorf("limit %d, want %d", q.Limit, tt.wantLimit)
+			}
+		})
+	}
+
+	s := newTestServer()
+	for range defaultPageSize + 1 {
+		createNews(t, s, newsRequest("bob"))
+	}
+	resp, err := s.ListNews(context.Background(), &newsv1.ListNewsRequest{})
+	if err != nil {
+		t.Fatalf("ListNews: %v", err)
+	}
+	if len(resp.News) != defaultPageSize || resp.NextPageToken == "" {
+		t.Errorf("got %d news and token %q, want a page of %d and a token", len(resp.News), resp.NextPageToken, defaultPageSize)
+	}
+}
+
+func TestPageTokenMustMatchRequest(t *testing.T) {
+	s := newTestServer()
+	for range 3 {
+		createNews(t, s, newsRequest("bob"))
+	}
+	first := &newsv1.ListNewsRequest{PageSize: 1, Author: "bob", OrderBy: "title"}
+	resp, err := s.ListNews(context.Background(), first)
+	if err != nil {
+		t.Fatalf("ListNews: %v", err)
+	}
+	token := resp.NextPageToken
+
+	// A token whose filter fingerprint was edited to fit another request.
+	decoded, err := decodePageToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded.Filter = "tampered"
+	raw, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := base64.RawURLEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		name     string
+		req      *newsv1.ListNewsRequest
+		wantCode codes.Code
+	}{
+		{"same request", &newsv1.ListNewsRequest{PageSize: 1, PageToken: token, Author: "bob", OrderBy: "title"}, codes.OK},
+		{"other page size", &newsv1.ListNewsRequest{PageSize: 2, PageToken: token, Author: "bob", OrderBy: "title"}, codes.OK},
+		{"changed filter", &newsv1.ListNewsRequest{PageSize: 1, PageToken: token, Author: "carol", OrderBy: "title"}, codes.InvalidArgument},
+		{"dropped filter", &newsv1.ListNewsRequest{PageSize: 1, PageToken: token, OrderBy: "title"}, codes.InvalidArgument},
+		{"changed order_by", &newsv1.ListNewsRequest{PageSize: 1, PageToken: token, Author: "bob", OrderBy: "title desc"}, codes.InvalidArgument},
+		{"tampered token", &newsv1.ListNewsRequest{PageSize: 1, PageToken: tampered, Author: "bob", OrderBy: "title"}, codes.InvalidArgument},
+		{"not base64", &newsv1.ListNewsRequest{PageSize: 1, PageToken: "!!garbage!!", Author: "bob", OrderBy: "title"}, codes.InvalidArgument},
+		{"not json", &newsv1.ListNewsRequest{PageSize: 1, PageToken: base64.RawURLEncoding.EncodeToString([]byte("garbage")), Author: "bob", OrderBy: "title"}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ListNews(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("code %v, want %v (%v)", got, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestPagingIsStableAcrossInserts(t *testing.T) {
+	for _, orderBy := range []string{"created_at", "created_at desc", "title"} {
+		t.Run(orderBy, func(t *testing.T) {
+			s := newTestServer()
+			var before []string
+			for i := range 10 {
+				in := newsRequest("bob")
+				in.Title = fmt.Sprintf("title %02d", 2*i)
+				before = append(before, createNews(t, s, in).Id)
+			}
+
+			var seen []string
+			req := &newsv1.ListNewsRequest{PageSize: 3, OrderBy: orderBy}
+			for page := 0; ; page++ {
+				resp, err := s.ListNews(context.Background(), req)
+				if err != nil {
+					t.Fatalf("page %d: %v", page, err)
+				}
+				for _, news := range resp.News {
+					seen = append(seen, news.Id)
+				}
+				if resp.NextPageToken == "" {
+					break
+				}
+				// News added between pages, sorting both before and
+				// after the cursor, must not shift the pages.
+				in := newsRequest("bob")
+				in.Title = fmt.Sprintf("title %02d", 2*page+1)
+				createNews(t, s, in)
+				req.PageToken = resp.NextPageToken
+			}
+
+			for _, id := range before {
+				if !slices.Contains(seen, id) {
+					t.Errorf("news %s that existed before paging was skipped", id)
+				}
+			}
+			sorted := slices.Clone(seen)
+			slices.Sort(sorted)
+			if len(slices.Compact(sorted)) != len(seen) {
+				t.Errorf("a news was listed twice: %v", seen)
+			}
+		})
+	}
+}
@@ -4,47 +4,89 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"github.com/sabuhigr/grpc-demo/internal/logging"
 	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	"github.com/sabuhigr/grpc-demo/internal/ratelimit"
 	"github.com/sabuhigr/grpc-demo/types"
-	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// maxBulkCreateItems caps how many items one BulkCreateNews stream may carry,
+// since they are buffered until the client closes the stream.
+const maxBulkCreateItems = 1000
+
 func init() {
 	log.SetFormatter(&log.JSONFormatter{})
 }
 
+// NewsStorer persists news. Implementations report failures with the
+// memstore sentinel errors (ErrNotFound, ErrAlreadyExists, ErrConflict),
+// wrapped as they see fit, and return ctx.Err() once ctx is done.
 type NewsStorer interface {
-	Create(news *memstore.News) *memstore.News
-	Get(id uuid.UUID) *memstore.News
-	GetAll() []*memstore.News
+	Create(ctx context.Context, news *memstore.News) (*memstore.News, error)
+	CreateBatch(ctx context.Context, batch []*memstore.News, allOrNothing bool) ([]memstore.BatchResult, error)
+	Get(ctx context.Context, id uuid.UUID, includeDeleted bool) (*memstore.News, error)
+	GetAll(ctx context.Context, includeDeleted bool) ([]*memstore.News, error)
+	Update(ctx context.Context, news *memstore.News, fields []string) (*memstore.News, error)
+	Delete(ctx context.Context, id uuid.UUID) (*memstore.News, error)
+	Restore(ctx context.Context, id uuid.UUID) (*memstore.News, error)
+	Purge(ctx context.Context, id uuid.UUID) error
+	Query(ctx context.Context, q memstore.Query) ([]*memstore.News, bool, error)
+	Watch(ctx context.Context, filter memstore.WatchFilter, resumeToken string) (*memstore.Subscription, error)
 }
 
 // Server gRPC server.
 type Server struct {
 	newsv1.UnimplementedNewsServiceServer
 	store NewsStorer
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
 }
 
 // NewServer creates a new gRPC server as pointer.
 func NewServer(store NewsStorer) *Server {
 	return &Server{
-		store: store,
+		store:    tracedStore{store},
+		shutdown: make(chan struct{}),
 	}
 }
 
+// Shutdown ends open GetAll and WatchNews streams with UNAVAILABLE, so a
+// graceful stop does not wait for them. Watchers can resume elsewhere from
+// their last resume token.
+func (s *Server) Shutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+}
+
+func (s *Server) shuttingDown() error {
+	return s.ErrorWithDetails(codes.Unavailable, types.ErrDetails{Code: 503, Message: "server is shutting down", Type: "shutting_down", Description: "retry, or resume from the last resume token"})
+}
+
 func (s *Server) ErrorWithDetails(code codes.Code, errDetails types.ErrDetails) error {
-	st := status.Newf(code, fmt.Sprintf("something went wrong: %v", errDetails.Message))
+	return errorWithDetails(code, errDetails)
+}
+
+func errorWithDetails(code codes.Code, errDetails types.ErrDetails) error {
+	st := status.New(code, fmt.Sprintf("something went wrong: %v", errDetails.Message))
 	v := &errdetails.PreconditionFailure_Violation{ //errDetails
 		Type:        errDetails.Type,
 		Subject:     errDetails.Message,
@@ -56,76 +98,399 @@ func (s *Server) ErrorWithDetails(code codes.Code, errDetails types.ErrDetails)
 	return st.Err()
 }
 
-func (s *Server) CreateNews(context context.Context, in *newsv1.CreateNewsRequest) (*newsv1.CreateNewsResponse, error) {
-	log := log.WithFields(
-		log.Fields{
-			"request_data": in,
-			"endpoint":     "GetNews",
-		})
+// checkReadDeleted lets only admins read soft deleted news.
+func (s *Server) checkReadDeleted(ctx context.Context) error {
+	if principal, ok := auth.FromContext(ctx); ok && principal.HasRole(auth.AdminRole) {
+		return nil
+	}
+	return s.ErrorWithDetails(codes.PermissionDenied, types.ErrDetails{Code: 403, Message: "only admins may read deleted news", Type: "include_deleted_not_allowed", Description: "include_deleted is for admins"})
+}
 
-	log.Debugf("Received request from client")
-	parsedNews, err := parseAndValidate(in)
+func (s *Server) CreateNews(ctx context.Context, in *newsv1.CreateNewsRequest) (*newsv1.CreateNewsResponse, error) {
+	parsedNews, err := validate(ctx, in)
 	if err != nil {
 		return nil, s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_argument", Description: "invalid argument"})
 	} else {
-		createdNews := s.store.Create(parsedNews)
-		log.WithFields(
-			logrus.Fields{
-				"status": "successfully",
-				"news":   createdNews,
-			},
-		).Infof("News created successfully!")
-		return toNewsResponse(createdNews), nil
-	}
-}
-func (s *Server) GetNews(context context.Context, in *newsv1.GetNewsRequest) (*newsv1.GetNewsResponse, error) {
-	log := log.WithFields(
-		log.Fields{
-			"request_data": in,
-			"endpoint":     "GetNews",
-		})
-
-	log.Debugf("Received request from client")
+		start := time.Now()
+		createdNews, err := s.store.Create(ctx, parsedNews)
+		if err != nil {
+			return nil, s.storeError(err, in.Id)
+		}
+		if retried(createdNews, start) {
+			ratelimit.Refund(ctx)
+		}
+		return toNewsResponse[*newsv1.CreateNewsResponse](createdNews), nil
+	}
+}
+func (s *Server) BulkCreateNews(stream newsv1.NewsService_BulkCreateNewsServer) error {
+	log := logging.FromContext(stream.Context())
+
+	var (
+		allOrNothing bool
+		results      []*newsv1.BulkCreateNewsResult
+		pending      []*memstore.News
+		// pendingIdx maps each pending item to its entry in results.
+		pendingIdx []int
+		failed     int
+	)
+	ctx, span := tracer.Start(stream.Context(), "BulkCreateNews.receive")
+	for index := int32(0); ; index++ {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			endSpan(span, err)
+			return err
+		}
+
+		if index == 0 {
+			allOrNothing = in.AllOrNothing
+		}
+		if index >= maxBulkCreateItems {
+			err := s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: fmt.Sprintf("at most %d items per stream", maxBulkCreateItems), Type: "too_many_items", Description: "split the batch"})
+			endSpan(span, err)
+			return err
+		}
+
+		parsedNews, err := validate(ctx, in.News)
+		if err != nil {
+			failed++
+			results = append(results, &newsv1.BulkCreateNewsResult{
+				Index: index,
+				Result: &newsv1.BulkCreateNewsResult_Error{
+					Error: status.Convert(s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_argument", Description: "invalid argument"})).Proto(),
+				},
+			})
+			continue
+		}
+
+		results = append(results, &newsv1.BulkCreateNewsResult{Index: index})
+		pending = append(pending, parsedNews)
+		pendingIdx = append(pendingIdx, len(results)-1)
+	}
+	span.SetAttributes(attribute.Int("batch.items", len(results)), attribute.Int("batch.invalid", failed))
+	span.End()
+
+	if allOrNothing && failed > 0 {
+		aborted := status.Newf(codes.Aborted, "batch aborted: %d of %d items are invalid", failed, len(results)).Proto()
+		for _, idx := range pendingIdx {
+			results[idx].Result = &newsv1.BulkCreateNewsResult_Error{Error: aborted}
+		}
+		log.WithField("failed", failed).Debugf("Bulk create aborted")
+		return stream.SendAndClose(&newsv1.BulkCreateNewsResponse{Results: results})
+	}
+
+	// Each item counts against the caller's daily CreateNews quota. Once it
+	// is used up, the remaining items fail with its error.
+	var (
+		releases []func()
+		quotaErr error
+	)
+	reserved, reservedIdx := pending[:0:0], pendingIdx[:0:0]
+	for i, news := range pending {
+		if quotaErr == nil {
+			release, err := ratelimit.ReserveItem(stream.Context())
+			if err == nil {
+				releases = append(releases, release)
+				reserved = append(reserved, news)
+				reservedIdx = append(reservedIdx, pendingIdx[i])
+				continue
+			}
+			quotaErr = err
+		}
+		failed++
+		results[pendingIdx[i]].Result = &newsv1.BulkCreateNewsResult_Error{Error: status.Convert(quotaErr).Proto()}
+	}
+	releaseAll := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+	if allOrNothing && quotaErr != nil {
+		releaseAll()
+		return quotaErr
+	}
+	pending, pendingIdx = reserved, reservedIdx
+
+	start := time.Now()
+	batchResults, err := s.store.CreateBatch(stream.Context(), pending, allOrNothing)
+	if err != nil {
+		releaseAll()
+		return s.storeError(err, "")
+	}
+
+	var created int32
+	for i, batchResult := range batchResults {
+		result := results[pendingIdx[i]]
+		if batchResult.Err != nil {
+			releases[i]()
+			failed++
+			result.Result = &newsv1.BulkCreateNewsResult_Error{Error: s.storeStatus(batchResult.Err, pending[i].ID.String()).Proto()}
+			continue
+		}
+		if retried(batchResult.News, start) {
+			releases[i]()
+		}
+		created++
+		result.Result = &newsv1.BulkCreateNewsResult_News{News: toNewsResponse[*newsv1.CreateNewsResponse](batchResult.News)}
+	}
+
+	return stream.SendAndClose(&newsv1.BulkCreateNewsResponse{
+		Results:      results,
+		CreatedCount: created,
+	})
+}
+
+func (s *Server) GetNews(ctx context.Context, in *newsv1.GetNewsRequest) (*newsv1.GetNewsResponse, error) {
 	parseUUID, err := uuid.Parse(in.Id)
 	if err != nil {
 		return nil, s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_UUID", Description: "invalid UUID"})
 	}
 
-	log.Debugf("uuid: %v", parseUUID)
+	if in.IncludeDeleted {
+		if err := s.checkReadDeleted(ctx); err != nil {
+			return nil, err
+		}
+	}
 
-	news := s.store.Get(parseUUID)
-	log.Debugf("news: %v", news)
-	if news == nil {
-		return nil, s.ErrorWithDetails(codes.NotFound, types.ErrDetails{Code: 500, Message: err.Error(), Type: "not_found", Description: "Not found"})
+	news, err := s.store.Get(ctx, parseUUID, in.IncludeDeleted)
+	if err != nil {
+		return nil, s.storeError(err, in.Id)
 	}
 
-	log.WithFields(
-		logrus.Fields{
-			"status": "successfully",
-			"news":   news,
-		},
-	).Infof("News got from memstore successfully!")
-	return toGetNewsResponse(news), nil
+	return toNewsResponse[*newsv1.GetNewsResponse](news), nil
 }
 
 func (s *Server) GetAll(in *emptypb.Empty, stream newsv1.NewsService_GetAllServer) error {
-	log := log.WithFields(
-		log.Fields{
-			"request_data": in,
-			"endpoint":     "GetAll",
-		},
-	)
+	newsList, err := s.store.GetAll(stream.Context(), false)
+	if err != nil {
+		return s.storeError(err, "")
+	}
+	for offset := 0; offset < len(newsList); offset += streamBatchSize {
+		select {
+		case <-s.shutdown:
+			return s.shuttingDown()
+		default:
+		}
+		batch := newsList[offset:min(offset+streamBatchSize, len(newsList))]
+		if err := s.sendBatch(stream, offset, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	log.Debugf("Received request from client")
-	newsList := s.store.GetAll()
-	for _, news := range newsList {
-		if err := stream.Send(toGetNewsResponse(news)); err != nil {
+// sendBatch sends part of a GetAll stream under a span of its own.
+func (s *Server) sendBatch(stream newsv1.NewsService_GetAllServer, offset int, batch []*memstore.News) (err error) {
+	_, span := tracer.Start(stream.Context(), "GetAll.send_batch", trace.WithAttributes(
+		attribute.Int("batch.offset", offset),
+		attribute.Int("batch.size", len(batch)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	for _, news := range batch {
+		if err := stream.Send(toNewsResponse[*newsv1.GetNewsResponse](news)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func (s *Server) UpdateNews(ctx context.Context, in *newsv1.UpdateNewsRequest) (*newsv1.UpdateNewsResponse, error) {
+	parseUUID, err := uuid.Parse(in.Id)
+	if err != nil {
+		return nil, s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_UUID", Description: "invalid UUID"})
+	}
+
+	fields, err := validateUpdateMask(in.UpdateMask)
+	if err != nil {
+		return nil, s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_update_mask", Description: "invalid update mask"})
+	}
+
+	existing, err := s.store.Get(ctx, parseUUID, false)
+	if err != nil {
+		return nil, s.storeError(err, in.Id)
+	}
+
+	// Validate the merged result, not just the patch, so an update can never
+	// leave a stored item in a state CreateNews would have rejected.
+	parsedNews, err := validate(ctx, mergeUpdate(existing, in, fields))
+	if err != nil {
+		return nil, s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_argument", Description: "invalid argument"})
+	}
+
+	updatedNews, err := s.store.Update(ctx, parsedNews, fields)
+	if err != nil {
+		return nil, s.storeError(err, in.Id)
+	}
+
+	return toNewsResponse[*newsv1.UpdateNewsResponse](updatedNews), nil
+}
+
+func (s *Server) DeleteNews(ctx context.Context, in *newsv1.DeleteNewsRequest) (*newsv1.DeleteNewsResponse, error) {
+	parseUUID, err := uuid.Parse(in.Id)
+	if err != nil {
+		return nil, s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_UUID", Description: "invalid UUID"})
+	}
+
+	deletedNews, err := s.store.Delete(ctx, parseUUID)
+	if err != nil {
+		return nil, s.storeError(err, in.Id)
+	}
+
+	return toNewsResponse[*newsv1.DeleteNewsResponse](deletedNews), nil
+}
+
+func (s *Server) RestoreNews(ctx context.Context, in *newsv1.RestoreNewsRequest) (*newsv1.RestoreNewsResponse, error) {
+	parseUUID, err := uuid.Parse(in.Id)
+	if err != nil {
+		return nil, s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_UUID", Description: "invalid UUID"})
+	}
+
+	restoredNews, err := s.store.Restore(ctx, parseUUID)
+	if err != nil {
+		return nil, s.storeError(err, in.Id)
+	}
+
+	return toNewsResponse[*newsv1.RestoreNewsResponse](restoredNews), nil
+}
+
+func (s *Server) PurgeNews(ctx context.Context, in *newsv1.PurgeNewsRequest) (*newsv1.PurgeNewsResponse, error) {
+	parseUUID, err := uuid.Parse(in.Id)
+	if err != nil {
+		return nil, s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_UUID", Description: "invalid UUID"})
+	}
+
+	if err := s.store.Purge(ctx, parseUUID); err != nil {
+		return nil, s.storeError(err, in.Id)
+	}
+
+	return &newsv1.PurgeNewsResponse{}, nil
+}
+
+func (s *Server) ListNews(ctx context.Context, in *newsv1.ListNewsRequest) (*newsv1.ListNewsResponse, error) {
+	if in.IncludeDeleted {
+		if err := s.checkReadDeleted(ctx); err != nil {
+			return nil, err
+		}
+	}
+	query, err := toQuery(in)
+	if err != nil {
+		return nil, s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_argument", Description: "invalid argument"})
+	}
+
+	newsList, more, err := s.store.Query(ctx, query)
+	if err != nil {
+		return nil, s.storeError(err, "")
+	}
+	resp := &newsv1.ListNewsResponse{
+		News: make([]*newsv1.GetNewsResponse, 0, len(newsList)),
+	}
+	for _, news := range newsList {
+		resp.News = append(resp.News, toNewsResponse[*newsv1.GetNewsResponse](news))
+	}
+	if more {
+		resp.NextPageToken = nextPageToken(in, query.OrderBy, newsList[len(newsList)-1])
+	}
+
+	return resp, nil
+}
+
+func (s *Server) WatchNews(in *newsv1.WatchNewsRequest, stream newsv1.NewsService_WatchNewsServer) error {
+	log := logging.FromContext(stream.Context()).WithField("request_data", in)
+
+	sub, err := s.store.Watch(stream.Context(), memstore.WatchFilter{Author: in.Author, Tags: in.Tags}, in.ResumeToken)
+	switch {
+	case errors.Is(err, memstore.ErrInvalidResumeToken):
+		return s.ErrorWithDetails(codes.InvalidArgument, types.ErrDetails{Code: 400, Message: err.Error(), Type: "invalid_resume_token", Description: "invalid resume token"})
+	case errors.Is(err, memstore.ErrResumeTokenExpired):
+		return s.ErrorWithDetails(codes.OutOfRange, types.ErrDetails{Code: 400, Message: err.Error(), Type: "resume_token_expired", Description: "watch again without a resume token"})
+	case err != nil:
+		return s.storeError(err, "")
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			log.Debugf("Watcher went away")
+			return nil
+		case <-s.shutdown:
+			log.Debugf("Ending watch for shutdown")
+			return s.shuttingDown()
+		case event, ok := <-sub.Events():
+			if !ok {
+				return s.ErrorWithDetails(codes.Aborted, types.ErrDetails{Code: 409, Message: sub.Err().Error(), Type: "slow_consumer", Description: "resume from the last resume token"})
+			}
+			_, span := tracer.Start(stream.Context(), "WatchNews.send", trace.WithAttributes(
+				attribute.String("news.id", event.News.ID.String()),
+			))
+			err := stream.Send(toWatchNewsResponse(event))
+			endSpan(span, err)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// validateUpdateMask checks the mask names only updatable fields and returns
+// them deduplicated.
+func validateUpdateMask(mask *fieldmaskpb.FieldMask) ([]string, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, errors.New("update_mask cannot be empty")
+	}
+
+	mask.Normalize()
+	var errs error
+	for _, path := range mask.GetPaths() {
+		switch path {
+		case memstore.FieldAuthor, memstore.FieldTitle, memstore.FieldSummary,
+			memstore.FieldContent, memstore.FieldSource, memstore.FieldTags:
+		default:
+			errs = errors.Join(errs, fmt.Errorf("field %q cannot be updated", path))
+		}
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+	return mask.GetPaths(), nil
+}
+
+// mergeUpdate builds a create request from the stored news with the masked
+// fields taken from the update request.
+func mergeUpdate(existing *memstore.News, in *newsv1.UpdateNewsRequest, fields []string) *newsv1.CreateNewsRequest {
+	merged := &newsv1.CreateNewsRequest{
+		Id:      existing.ID.String(),
+		Author:  existing.Author,
+		Title:   existing.Title,
+		Summary: existing.Summary,
+		Content: existing.Content,
+		Source:  existing.Source.String(),
+		Tags:    existing.Tags,
+	}
+
+	for _, field := range fields {
+		switch field {
+		case memstore.FieldAuthor:
+			merged.Author = in.Author
+		case memstore.FieldTitle:
+			merged.Title = in.Title
+		case memstore.FieldSummary:
+			merged.Summary = in.Summary
+		case memstore.FieldContent:
+			merged.Content = in.Content
+		case memstore.FieldSource:
+			merged.Source = in.Source
+		case memstore.FieldTags:
+			merged.Tags = in.Tags
+		}
+	}
+	return merged
+}
+
 func parseAndValidate(in *newsv1.CreateNewsRequest) (n *memstore.News, errs error) {
 	errs = nil
 	if in == nil {
@@ -180,16 +545,25 @@ func parseAndValidate(in *newsv1.CreateNewsRequest) (n *memstore.News, errs erro
 		Tags:      in.Tags,
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
-		DeletedAt: time.Now().UTC(),
 	}, nil
 }
 
-func toNewsResponse(news *memstore.News) *newsv1.CreateNewsResponse {
+// newsResponse is a response message carrying one news. They all number
+// their fields like GetNewsResponse.
+type newsResponse interface {
+	*newsv1.CreateNewsResponse | *newsv1.GetNewsResponse | *newsv1.UpdateNewsResponse |
+		*newsv1.DeleteNewsResponse | *newsv1.RestoreNewsResponse
+	proto.Message
+}
+
+// toNewsResponse converts news to the response message T.
+func toNewsResponse[T newsResponse](news *memstore.News) T {
+	var resp T
 	if news == nil {
-		return nil
+		return resp
 	}
 
-	return &newsv1.CreateNewsResponse{
+	src := (&newsv1.GetNewsResponse{
 		Id:        news.ID.String(),
 		Author:    news.Author,
 		Title:     news.Title,
@@ -199,23 +573,53 @@ func toNewsResponse(news *memstore.News) *newsv1.CreateNewsResponse {
 		Tags:      news.Tags,
 		CreatedAt: timestamppb.New(news.CreatedAt.UTC()),
 		UpdatedAt: timestamppb.New(news.UpdatedAt.UTC()),
+		DeletedAt: deletedAt(news),
+	}).ProtoReflect()
+	if msg, ok := src.Interface().(T); ok {
+		return msg
 	}
+	dst := resp.ProtoReflect().New()
+	fields := dst.Descriptor().Fields()
+	src.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		dst.Set(fields.ByNumber(fd.Number()), v)
+		return true
+	})
+	return dst.Interface().(T)
 }
 
-func toGetNewsResponse(news *memstore.News) *newsv1.GetNewsResponse {
-	if news == nil {
-		return nil
+func toWatchNewsResponse(event memstore.Event) *newsv1.WatchNewsResponse {
+	var eventType newsv1.WatchNewsEventType
+	switch event.Type {
+	case memstore.EventCreated:
+		eventType = newsv1.WatchNewsEventType_WATCH_NEWS_EVENT_TYPE_CREATED
+	case memstore.EventUpdated:
+		eventType = newsv1.WatchNewsEventType_WATCH_NEWS_EVENT_TYPE_UPDATED
+	case memstore.EventDeleted:
+		eventType = newsv1.WatchNewsEventType_WATCH_NEWS_EVENT_TYPE_DELETED
+	case memstore.EventRestored:
+		eventType = newsv1.WatchNewsEventType_WATCH_NEWS_EVENT_TYPE_RESTORED
+	case memstore.EventPurged:
+		eventType = newsv1.WatchNewsEventType_WATCH_NEWS_EVENT_TYPE_PURGED
 	}
 
-	return &newsv1.GetNewsResponse{
-		Id:        news.ID.String(),
-		Author:    news.Author,
-		Title:     news.Title,
-		Summary:   news.Summary,
-		Content:   news.Content,
-		Source:    news.Source.String(),
-		Tags:      news.Tags,
-		CreatedAt: timestamppb.New(news.CreatedAt.UTC()),
-		UpdatedAt: timestamppb.New(news.UpdatedAt.UTC()),
+	return &newsv1.WatchNewsResponse{
+		Type:        eventType,
+		News:        toNewsResponse[*newsv1.GetNewsResponse](event.News),
+		EventTime:   timestamppb.New(event.Time),
+		ResumeToken: event.ResumeToken,
+	}
+}
+
+// deletedAt returns the tombstone timestamp, or nil for live news.
+func deletedAt(news *memstore.News) *timestamppb.Timestamp {
+	if news.DeletedAt.IsZero() {
+		return nil
 	}
+	return timestamppb.New(news.DeletedAt.UTC())
+}
+
+// retried reports whether the store answered a create started at start with
+// news it already held, i.e. the create was a retry that stored nothing.
+func retried(news *memstore.News, start time.Time) bool {
+	return news.CreatedAt.Before(start)
 }
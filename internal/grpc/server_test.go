@@ -0,0 +1,195 @@
+package grpc
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// newTestServer returns a server backed by an empty memstore.
+func newTestServer() *Server {
+	return NewServer(memstore.New())
+}
+
+// newsRequest returns a valid create request by author.
+func newsRequest(author string) *newsv1.CreateNewsRequest {
+	return &newsv1.CreateNewsRequest{
+		Id:      uuid.NewString(),
+		Author:  author,
+		Title:   "Title",
+		Summary: "Summary",
+		Content: "Content",
+		Source:  "https://example.com/news",
+		Tags:    []string{"go"},
+	}
+}
+
+func createNews(t *testing.T, s *Server, in *newsv1.CreateNewsRequest) *newsv1.CreateNewsResponse {
+	t.Helper()
+	created, err := s.CreateNews(context.Background(), in)
+	if err != nil {
+		t.Fatalf("CreateNews: %v", err)
+	}
+	return created
+}
+
+func TestReadDeletedIsForAdmins(t *testing.T) {
+	s := newTestServer()
+	deleted := createNews(t, s, newsRequest("bob"))
+	if _, err := s.DeleteNews(context.Background(), &newsv1.DeleteNewsRequest{Id: deleted.Id}); err != nil {
+		t.Fatalf("DeleteNews: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		{"admin", auth.NewContext(context.Background(), &auth.Principal{Subject: "alice", Roles: []string{auth.AdminRole}}), codes.OK},
+		{"author", auth.NewContext(context.Background(), &auth.Principal{Subject: "bob", Roles: []string{"editor"}}), codes.PermissionDenied},
+		{"unauthenticated", context.Background(), codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetNews(tt.ctx, &newsv1.GetNewsRequest{Id: deleted.Id, IncludeDeleted: true})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("GetNews: code %v, want %v (%v)", code, tt.wantCode, err)
+			}
+			if err == nil && got.DeletedAt == nil {
+				t.Error("GetNews returned the news without deleted_at")
+			}
+
+			list, err := s.ListNews(tt.ctx, &newsv1.ListNewsRequest{IncludeDeleted: true})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("ListNews: code %v, want %v (%v)", code, tt.wantCode, err)
+			}
+			if err == nil && len(list.News) != 1 {
+				t.Errorf("ListNews returned %d news, want the deleted one", len(list.News))
+			}
+		})
+	}
+
+	// Without include_deleted nobody needs to be an admin, and deleted news
+	// stays hidden.
+	if _, err := s.GetNews(context.Background(), &newsv1.GetNewsRequest{Id: deleted.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetNews of deleted news: %v, want NotFound", err)
+	}
+}
+
+func TestUpdateNews(t *testing.T) {
+	mask := func(paths ...string) *fieldmaskpb.FieldMask { return &fieldmaskpb.FieldMask{Paths: paths} }
+	tests := []struct {
+		name     string
+		update   *newsv1.UpdateNewsRequest
+		wantCode codes.Code
+		// want edits the created news into the expected result.
+		want func(n *newsv1.GetNewsResponse)
+	}{
+		{
+			name:   "partial merge keeps the other fields",
+			update: &newsv1.UpdateNewsRequest{Title: "New title", Content: "not in the mask", UpdateMask: mask("title")},
+			want:   func(n *newsv1.GetNewsResponse) { n.Title = "New title" },
+		},
+		{
+			name:   "several fields",
+			update: &newsv1.UpdateNewsRequest{Summary: "New summary", Tags: []string{"rust"}, UpdateMask: mask("tags", "summary")},
+			want:   func(n *newsv1.GetNewsResponse) { n.Summary, n.Tags = "New summary", []string{"rust"} },
+		},
+		{name: "unknown path", update: &newsv1.UpdateNewsRequest{UpdateMask: mask("title", "nope")}, wantCode: codes.InvalidArgument},
+		{name: "immutable path", update: &newsv1.UpdateNewsRequest{UpdateMask: mask("created_at")}, wantCode: codes.InvalidArgument},
+		{name: "missing mask", update: &newsv1.UpdateNewsRequest{Title: "New title"}, wantCode: codes.InvalidArgument},
+		{name: "empty mask", update: &newsv1.UpdateNewsRequest{Title: "New title", UpdateMask: mask()}, wantCode: codes.InvalidArgument},
+		{name: "merged result clears a required field", update: &newsv1.UpdateNewsRequest{UpdateMask: mask("title")}, wantCode: codes.InvalidArgument},
+		{name: "merged result has no tags", update: &newsv1.UpdateNewsRequest{Title: "New title", UpdateMask: mask("title", "tags")}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			created := createNews(t, s, newsRequest("bob"))
+			tt.update.Id = created.Id
+
+			updated, err := s.UpdateNews(context.Background(), tt.update)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code %v, want %v (%v)", got, tt.wantCode, err)
+			}
+
+			stored, err := s.GetNews(context.Background(), &newsv1.GetNewsRequest{Id: created.Id})
+			if err != nil {
+				t.Fatalf("GetNews: %v", err)
+			}
+			want := &newsv1.GetNewsResponse{
+				Author:  created.Author,
+				Title:   created.Title,
+				Summary: created.Summary,
+				Content: created.Content,
+				Source:  created.Source,
+				Tags:    created.Tags,
+			}
+			if tt.want != nil {
+				tt.want(want)
+				if updated.Title != want.Title || !updated.UpdatedAt.AsTime().After(created.UpdatedAt.AsTime()) {
+					t.Errorf("response %v does not carry the update", updated)
+				}
+			}
+			if stored.Author != want.Author || stored.Title != want.Title || stored.Summary != want.Summary ||
+				stored.Content != want.Content || stored.Source != want.Source || !slices.Equal(stored.Tags, want.Tags) {
+				t.Errorf("stored %v, want %v", stored, want)
+			}
+		})
+	}
+}
+
+func TestToNewsResponse(t *testing.T) {
+	news := &memstore.News{
+		ID:        uuid.New(),
+		Author:    "bob",
+		Title:     "Title",
+		Summary:   "Summary",
+		Content:   "Content",
+		Source:    &url.URL{Scheme: "https", Host: "example.com"},
+		Tags:      []string{"go", "grpc"},
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		DeletedAt: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+	}
+	want := toNewsResponse[*newsv1.GetNewsResponse](news)
+	if want.Id != news.ID.String() || want.Source != "https://example.com" || want.DeletedAt.AsTime() != news.DeletedAt {
+		t.Fatalf("GetNewsResponse %v does not match %+v", want, news)
+	}
+
+	// Every response carries the same fields, so each decodes as a
+	// GetNewsResponse equal to want.
+	for _, resp := range []proto.Message{
+		toNewsResponse[*newsv1.CreateNewsResponse](news),
+		toNewsResponse[*newsv1.UpdateNewsResponse](news),
+		toNewsResponse[*newsv1.DeleteNewsResponse](news),
+		toNewsResponse[*newsv1.RestoreNewsResponse](news),
+	} {
+		raw, err := proto.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := &newsv1.GetNewsResponse{}
+		if err := proto.Unmarshal(raw, got); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got, want) {
+			t.Errorf("%T = %v, want %v", resp, resp, want)
+		}
+	}
+
+	if resp := toNewsResponse[*newsv1.DeleteNewsResponse](nil); resp != nil {
+		t.Errorf("nil news converted to %v", resp)
+	}
+}
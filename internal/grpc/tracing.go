@@ -0,0 +1,124 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// streamBatchSize is how many messages of a stream are sent under one span.
+const streamBatchSize = 100
+
+var tracer = otel.Tracer("github.com/sabuhigr/grpc-demo/internal/grpc")
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// validate is parseAndValidate in a span of its own.
+func validate(ctx context.Context, in *newsv1.CreateNewsRequest) (*memstore.News, error) {
+	_, span := tracer.Start(ctx, "validate")
+	news, err := parseAndValidate(in)
+	endSpan(span, err)
+	return news, err
+}
+
+// tracedStore wraps every store call in a span.
+type tracedStore struct {
+	NewsStorer
+}
+
+func (s tracedStore) start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "NewsStorer."+op, trace.WithAttributes(attrs...))
+}
+
+func newsID(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("news.id", id.String())
+}
+
+func (s tracedStore) Create(ctx context.Context, news *memstore.News) (*memstore.News, error) {
+	ctx, span := s.start(ctx, "Create", newsID(news.ID))
+	created, err := s.NewsStorer.Create(ctx, news)
+	endSpan(span, err)
+	return created, err
+}
+
+func (s tracedStore) CreateBatch(ctx context.Context, batch []*memstore.News, allOrNothing bool) ([]memstore.BatchResult, error) {
+	ctx, span := s.start(ctx, "CreateBatch",
+		attribute.Int("batch.size", len(batch)),
+		attribute.Bool("batch.all_or_nothing", allOrNothing),
+	)
+	results, err := s.NewsStorer.CreateBatch(ctx, batch, allOrNothing)
+	endSpan(span, err)
+	return results, err
+}
+
+func (s tracedStore) Get(ctx context.Context, id uuid.UUID, includeDeleted bool) (*memstore.News, error) {
+	ctx, span := s.start(ctx, "Get", newsID(id))
+	news, err := s.NewsStorer.Get(ctx, id, includeDeleted)
+	endSpan(span, err)
+	return news, err
+}
+
+func (s tracedStore) GetAll(ctx context.Context, includeDeleted bool) ([]*memstore.News, error) {
+	ctx, span := s.start(ctx, "GetAll")
+	all, err := s.NewsStorer.GetAll(ctx, includeDeleted)
+	span.SetAttributes(attribute.Int("result.count", len(all)))
+	endSpan(span, err)
+	return all, err
+}
+
+func (s tracedStore) Update(ctx context.Context, news *memstore.News, fields []string) (*memstore.News, error) {
+	ctx, span := s.start(ctx, "Update", newsID(news.ID), attribute.StringSlice("update.fields", fields))
+	updated, err := s.NewsStorer.Update(ctx, news, fields)
+	endSpan(span, err)
+	return updated, err
+}
+
+func (s tracedStore) Delete(ctx context.Context, id uuid.UUID) (*memstore.News, error) {
+	ctx, span := s.start(ctx, "Delete", newsID(id))
+	deleted, err := s.NewsStorer.Delete(ctx, id)
+	endSpan(span, err)
+	return deleted, err
+}
+
+func (s tracedStore) Restore(ctx context.Context, id uuid.UUID) (*memstore.News, error) {
+	ctx, span := s.start(ctx, "Restore", newsID(id))
+	restored, err := s.NewsStorer.Restore(ctx, id)
+	endSpan(span, err)
+	return restored, err
+}
+
+func (s tracedStore) Purge(ctx context.Context, id uuid.UUID) error {
+	ctx, span := s.start(ctx, "Purge", newsID(id))
+	err := s.NewsStorer.Purge(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (s tracedStore) Query(ctx context.Context, q memstore.Query) ([]*memstore.News, bool, error) {
+	ctx, span := s.start(ctx, "Query", attribute.Int("query.limit", q.Limit))
+	news, more, err := s.NewsStorer.Query(ctx, q)
+	span.SetAttributes(attribute.Int("result.count", len(news)), attribute.Bool("result.more", more))
+	endSpan(span, err)
+	return news, more, err
+}
+
+func (s tracedStore) Watch(ctx context.Context, filter memstore.WatchFilter, resumeToken string) (*memstore.Subscription, error) {
+	// The subscription lives as long as ctx; only setting it up is traced.
+	_, span := s.start(ctx, "Watch", attribute.Bool("watch.resumed", resumeToken != ""))
+	sub, err := s.NewsStorer.Watch(ctx, filter, resumeToken)
+	endSpan(span, err)
+	return sub, err
+}
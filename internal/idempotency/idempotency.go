@@ -0,0 +1,164 @@
+// Package idempotency replays the response of a unary call that is retried
+// with the same idempotency-key metadata value, so clients can safely retry
+// creates after a timeout or a dropped connection.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// KeyHeader is the metadata key carrying the client chosen key.
+	KeyHeader = "idempotency-key"
+	// ReplayedHeader is set on responses served from the cache.
+	ReplayedHeader = "idempotency-replayed"
+
+	maxKeyLength  = 255
+	sweepInterval = time.Minute
+)
+
+// Cache remembers successful responses per method and key for the retention
+// window. Failed calls are forgotten, so they can be retried.
+type Cache struct {
+	retention time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+type entry struct {
+	fingerprint [sha256.Size]byte
+	// resp is nil while the first call is still running.
+	resp    proto.Message
+	expires time.Time
+}
+
+// New creates a cache keeping responses for retention.
+func New(retention time.Duration) *Cache {
+	return &Cache{
+		retention: retention,
+		entries:   make(map[string]*entry),
+		lastSweep: time.Now(),
+	}
+}
+
+// UnaryServerInterceptor makes the given full method names idempotent.
+// Calls without an idempotency key, or to other methods, pass through.
+func (c *Cache) UnaryServerInterceptor(methods ...string) grpc.UnaryServerInterceptor {
+	idempotent := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		idempotent[method] = true
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key := metadata.ValueFromIncomingContext(ctx, KeyHeader)
+		if !idempotent[info.FullMethod] || len(key) == 0 || key[0] == "" {
+			return handler(ctx, req)
+		}
+		if len(key[0]) > maxKeyLength {
+			return nil, invalidKey("must be at most 255 characters")
+		}
+
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "cannot fingerprint request")
+		}
+		fingerprint := sha256.Sum256(append([]byte(info.FullMethod+"\x00"), raw...))
+		// Keys are scoped to the caller, so one client can never be served
+		// another client's response.
+		var subject string
+		if principal, ok := auth.FromContext(ctx); ok {
+			subject = principal.Subject
+		}
+		cacheKey := info.FullMethod + "\x00" + subject + "\x00" + key[0]
+
+		resp, err := c.begin(cacheKey, fingerprint)
+		if err != nil || resp != nil {
+			if resp != nil {
+				_ = grpc.SetHeader(ctx, metadata.Pairs(ReplayedHeader, "true"))
+			}
+			return resp, err
+		}
+
+		// Deferred, so the key is released when the handler panics too and a
+		// retry can run again.
+		var out any
+		defer func() { c.finish(cacheKey, out, err) }()
+		out, err = handler(ctx, req)
+		return out, err
+	}
+}
+
+// begin claims the key for a new call, or returns the cached response of an
+// earlier call with the same request.
+func (c *Cache) begin(cacheKey string, fingerprint [sha256.Size]byte) (proto.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > sweepInterval {
+		for k, e := range c.entries {
+			if e.resp != nil && now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	e, ok := c.entries[cacheKey]
+	switch {
+	case !ok || (e.resp != nil && now.After(e.expires)):
+		c.entries[cacheKey] = &entry{fingerprint: fingerprint}
+		return nil, nil
+	case e.fingerprint != fingerprint:
+		return nil, invalidKey("was already used for a different request")
+	case e.resp == nil:
+		return nil, status.Error(codes.Aborted, "a request with this idempotency key is still in progress")
+	default:
+		return proto.Clone(e.resp), nil
+	}
+}
+
+// finish caches a successful response, or releases the key after a failure.
+func (c *Cache) finish(cacheKey string, resp any, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msg, ok := resp.(proto.Message)
+	if err != nil || !ok {
+		delete(c.entries, cacheKey)
+		return
+	}
+	e := c.entries[cacheKey]
+	e.resp = proto.Clone(msg)
+	e.expires = time.Now().Add(c.retention)
+}
+
+func invalidKey(reason string) error {
+	st := status.New(codes.InvalidArgument, "idempotency key "+reason)
+	withDetails, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: KeyHeader, Description: reason},
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
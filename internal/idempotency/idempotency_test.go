@@ -0,0 +1,71 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const testMethod = "/test.Service/Create"
+
+func call(t *testing.T, interceptor grpc.UnaryServerInterceptor, handler grpc.UnaryHandler) (resp any, panicked bool, err error) {
+	t.Helper()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(KeyHeader, "k1"))
+	defer func() {
+		if recover() != nil {
+			panicked = true
+		}
+	}()
+	resp, err = interceptor(ctx, wrapperspb.String("req"), &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+	return resp, false, err
+}
+
+func TestReplaysSuccessfulResponse(t *testing.T) {
+	interceptor := New(time.Hour).UnaryServerInterceptor(testMethod)
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		return wrapperspb.Int32(int32(calls)), nil
+	}
+
+	first, _, err := call(t, interceptor, handler)
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	second, _, err := call(t, interceptor, handler)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+	if !proto.Equal(first.(proto.Message), second.(proto.Message)) {
+		t.Errorf("retry got %v, want %v", second, first)
+	}
+}
+
+func TestPanicReleasesKey(t *testing.T) {
+	interceptor := New(time.Hour).UnaryServerInterceptor(testMethod)
+
+	_, panicked, _ := call(t, interceptor, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	if !panicked {
+		t.Fatal("handler panic was swallowed")
+	}
+
+	resp, _, err := call(t, interceptor, func(ctx context.Context, req any) (any, error) {
+		return wrapperspb.Int32(1), nil
+	})
+	if err != nil {
+		t.Fatalf("retry after panic: %v", err)
+	}
+	if got := resp.(*wrapperspb.Int32Value).GetValue(); got != 1 {
+		t.Errorf("retry got %d, want 1", got)
+	}
+}
@@ -0,0 +1,223 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// RequestIDHeader carries the request id in both directions.
+	RequestIDHeader = "x-request-id"
+
+	requestIDField  = "request_id"
+	maxRequestIDLen = 128
+	redacted        = "[REDACTED]"
+)
+
+// sensitiveHeaders are never logged in the clear.
+var sensitiveHeaders = []string{"authorization", "proxy-authorization", "cookie", "x-api-key"}
+
+// Interceptor logs each call once when it finishes.
+type Interceptor struct {
+	logger *log.Logger
+	redact map[string]bool
+}
+
+// NewInterceptor logs to logger, redacting the authorization and cookie
+// headers.
+func NewInterceptor(logger *log.Logger) *Interceptor {
+	i := &Interceptor{logger: logger, redact: make(map[string]bool)}
+	return i.Redact(sensitiveHeaders...)
+}
+
+// Redact hides the values of more metadata keys.
+func (i *Interceptor) Redact(keys ...string) *Interceptor {
+	for _, key := range keys {
+		i.redact[strings.ToLower(key)] = true
+	}
+	return i
+}
+
+// Unary returns the interceptor for unary calls. It should come first in
+// the chain so it sees calls rejected by later interceptors.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, requestID := i.begin(ctx, info.FullMethod)
+		if err := grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID)); err != nil {
+			FromContext(ctx).WithError(err).Debug("Failed to send request id")
+		}
+
+		resp, err := handler(ctx, req)
+
+		fields := log.Fields{
+			"request_bytes":  size(req),
+			"response_bytes": size(resp),
+		}
+		i.finish(ctx, start, fields, err)
+		return resp, err
+	}
+}
+
+// Stream returns the interceptor for streaming calls. Sizes are summed over
+// all messages of the stream.
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, requestID := i.begin(ss.Context(), info.FullMethod)
+		if err := ss.SetHeader(metadata.Pairs(RequestIDHeader, requestID)); err != nil {
+			FromContext(ctx).WithError(err).Debug("Failed to send request id")
+		}
+
+		counted := &countingStream{ServerStream: ss, ctx: ctx}
+		err := handler(srv, counted)
+
+		fields := log.Fields{
+			"request_bytes":     counted.received.Load(),
+			"response_bytes":    counted.sent.Load(),
+			"messages_received": counted.receivedCount.Load(),
+			"messages_sent":     counted.sentCount.Load(),
+		}
+		i.finish(ctx, start, fields, err)
+		return err
+	}
+}
+
+// begin puts the call logger into ctx.
+func (i *Interceptor) begin(ctx context.Context, method string) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := ""
+	if values := md.Get(RequestIDHeader); len(values) > 0 && validRequestID(values[0]) {
+		requestID = values[0]
+	} else {
+		requestID = uuid.NewString()
+	}
+
+	fields := log.Fields{
+		requestIDField: requestID,
+		"endpoint":     method,
+		"peer":         peerAddr(ctx),
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		fields["trace_id"] = sc.TraceID().String()
+	}
+	entry := i.logger.WithFields(fields)
+	// Only the call log line carries the metadata.
+	fields["metadata"] = i.redacted(md)
+	return newContext(ctx, entry, fields), requestID
+}
+
+func (i *Interceptor) finish(ctx context.Context, start time.Time, fields log.Fields, err error) {
+	s := ctx.Value(contextKey{}).(*scope)
+	for k, v := range s.call.snapshot() {
+		fields[k] = v
+	}
+	st := status.Convert(err)
+	fields["code"] = st.Code().String()
+	fields["duration_ms"] = float64(time.Since(start).Microseconds()) / 1000
+
+	entry := i.logger.WithFields(fields)
+	if err != nil {
+		entry = entry.WithField("error", st.Message())
+	}
+	entry.Log(level(st.Code()), "Call finished")
+}
+
+// redacted flattens md for logging, hiding sensitive and binary values.
+func (i *Interceptor) redacted(md metadata.MD) map[string]string {
+	out := make(map[string]string, len(md))
+	for key, values := range md {
+		switch {
+		case i.redact[key]:
+			out[key] = redacted
+		case strings.HasSuffix(key, "-bin"):
+			out[key] = "[binary]"
+		default:
+			out[key] = strings.Join(values, ",")
+		}
+	}
+	return out
+}
+
+// level logs server faults as errors and caller mistakes as warnings.
+func level(code codes.Code) log.Level {
+	switch code {
+	case codes.OK:
+		return log.InfoLevel
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented, codes.Unavailable:
+		return log.ErrorLevel
+	default:
+		return log.WarnLevel
+	}
+}
+
+// validRequestID accepts ids short and plain enough to log and echo back.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
+
+func size(m any) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+	return 0
+}
+
+// countingStream carries the call logger and counts the messages of a
+// stream.
+type countingStream struct {
+	grpc.ServerStream
+	ctx context.Context
+
+	received, sent           atomic.Int64
+	receivedCount, sentCount atomic.Int64
+}
+
+func (s *countingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *countingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.received.Add(int64(size(m)))
+	s.receivedCount.Add(1)
+	return nil
+}
+
+func (s *countingStream) SendMsg(m any) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.sent.Add(int64(size(m)))
+	s.sentCount.Add(1)
+	return nil
+}
@@ -0,0 +1,72 @@
+// Package logging logs every gRPC call once, with a request id, and hands
+// handlers a logger scoped to the call.
+package logging
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type contextKey struct{}
+
+// scope is what a context carries: the logger handlers use and the fields
+// of the call log line they share with the interceptor.
+type scope struct {
+	entry *log.Entry
+	call  *callFields
+}
+
+type callFields struct {
+	mu     sync.Mutex
+	fields log.Fields
+}
+
+func (c *callFields) add(fields log.Fields) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, v := range fields {
+		c.fields[k] = v
+	}
+}
+
+func (c *callFields) snapshot() log.Fields {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fields := make(log.Fields, len(c.fields))
+	for k, v := range c.fields {
+		fields[k] = v
+	}
+	return fields
+}
+
+func newContext(ctx context.Context, entry *log.Entry, fields log.Fields) context.Context {
+	return context.WithValue(ctx, contextKey{}, &scope{entry: entry, call: &callFields{fields: fields}})
+}
+
+// FromContext returns the logger of the call, or the standard logger outside
+// of one.
+func FromContext(ctx context.Context) *log.Entry {
+	if s, ok := ctx.Value(contextKey{}).(*scope); ok {
+		return s.entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// WithFields adds fields to the logger of the call and to the line logged
+// when it finishes, e.g. the principal once it is authenticated.
+func WithFields(ctx context.Context, fields log.Fields) context.Context {
+	s, ok := ctx.Value(contextKey{}).(*scope)
+	if !ok {
+		return ctx
+	}
+	s.call.add(fields)
+	return context.WithValue(ctx, contextKey{}, &scope{entry: s.entry.WithFields(fields), call: s.call})
+}
+
+// RequestID returns the request id of the call, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := FromContext(ctx).Data[requestIDField].(string)
+	return id
+}
@@ -0,0 +1,159 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// lastUsedGranularity is how stale LastUsedAt may get. Uses within it are
+// not committed, so busy keys do not write on every call.
+const lastUsedGranularity = time.Minute
+
+// APIKey is a stored API key. Only a hash of the secret is kept.
+type APIKey struct {
+	ID          uuid.UUID
+	Name, Owner string
+	Scopes      []string
+	Hash        []byte
+	// PreviousHash keeps the secret replaced by the last rotation valid
+	// until PreviousExpiresAt.
+	PreviousHash      []byte
+	PreviousExpiresAt time.Time
+
+	CreatedAt, ExpiresAt, LastUsedAt, RevokedAt time.Time
+}
+
+// CreateAPIKey stores the key and sets CreatedAt. It returns
+// ErrAlreadyExists if the id is taken.
+func (s *Store) CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.apiKeys[key.ID]; ok {
+		return nil, fmt.Errorf("api key %w: %s", ErrAlreadyExists, key.ID)
+	}
+
+	created := *key
+	created.CreatedAt = time.Now().UTC()
+	if err := s.commit(ctx, Change{Type: EventCreated, APIKey: &created}); err != nil {
+		return nil, err
+	}
+	s.apiKeys[created.ID] = &created
+	return &created, nil
+}
+
+// GetAPIKey returns the key with the id, revoked or not, or ErrNotFound.
+func (s *Store) GetAPIKey(ctx context.Context, id uuid.UUID) (*APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if key, ok := s.apiKeys[id]; ok {
+		return key, nil
+	}
+	return nil, apiKeyNotFound(id)
+}
+
+// ListAPIKeys returns the keys of owner, or of everyone if owner is empty,
+// oldest first. Revoked keys are only returned when includeRevoked is set.
+func (s *Store) ListAPIKeys(ctx context.Context, owner string, includeRevoked bool) ([]*APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	keys := make([]*APIKey, 0)
+	for _, key := range s.apiKeys {
+		if (owner == "" || key.Owner == owner) && (includeRevoked || key.RevokedAt.IsZero()) {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b *APIKey) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), slices.Compare(a.ID[:], b.ID[:]))
+	})
+	return keys, nil
+}
+
+// RevokeAPIKey sets RevokedAt. It returns ErrNotFound for an unknown id and
+// ErrConflict if the key is already revoked.
+func (s *Store) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*APIKey, error) {
+	return s.updateAPIKey(ctx, id, func(key *APIKey) error {
+		if !key.RevokedAt.IsZero() {
+			return fmt.Errorf("%w: api key %s is already revoked", ErrConflict, id)
+		}
+		key.RevokedAt = time.Now().UTC()
+		return nil
+	})
+}
+
+// RotateAPIKey replaces the secret hash of the key. The old secret keeps
+// working for grace. It returns ErrNotFound for an unknown id and
+// ErrConflict if the key is revoked.
+func (s *Store) RotateAPIKey(ctx context.Context, id uuid.UUID, hash []byte, grace time.Duration) (*APIKey, error) {
+	return s.updateAPIKey(ctx, id, func(key *APIKey) error {
+		if !key.RevokedAt.IsZero() {
+			return fmt.Errorf("%w: api key %s is revoked", ErrConflict, id)
+		}
+		key.PreviousHash, key.PreviousExpiresAt = nil, time.Time{}
+		if grace > 0 {
+			key.PreviousHash, key.PreviousExpiresAt = key.Hash, time.Now().UTC().Add(grace)
+		}
+		key.Hash = hash
+		return nil
+	})
+}
+
+// TouchAPIKey records a use of the key at the given time. Uses within a
+// minute of the recorded one are not committed.
+func (s *Store) TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	s.lock.RLock()
+	key, ok := s.apiKeys[id]
+	fresh := ok && at.Sub(key.LastUsedAt) < lastUsedGranularity
+	s.lock.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	_, err := s.updateAPIKey(ctx, id, func(key *APIKey) error {
+		if at.After(key.LastUsedAt) {
+			key.LastUsedAt = at.UTC()
+		}
+		return nil
+	})
+	return err
+}
+
+// updateAPIKey applies fn to a copy of the key and commits it.
+func (s *Store) updateAPIKey(ctx context.Context, id uuid.UUID, fn func(key *APIKey) error) (*APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	existing, ok := s.apiKeys[id]
+	if !ok {
+		return nil, apiKeyNotFound(id)
+	}
+
+	updated := *existing
+	if err := fn(&updated); err != nil {
+		return nil, err
+	}
+	if err := s.commit(ctx, Change{Type: EventUpdated, APIKey: &updated}); err != nil {
+		return nil, err
+	}
+	s.apiKeys[id] = &updated
+	return &updated, nil
+}
@@ -0,0 +1,69 @@
+package memstore_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	"github.com/sabuhigr/grpc-demo/internal/storetest"
+)
+
+var benchSizes = []int{1_000, 10_000, 100_000}
+
+// benchTags is how many distinct tags the benchmark news spread over.
+const benchTags = 100
+
+// filled returns a store holding n news, tagged round robin, and their IDs.
+func filled(b *testing.B, n int) (*memstore.Store, []uuid.UUID) {
+	b.Helper()
+	s := memstore.New()
+	batch := make([]*memstore.News, n)
+	ids := make([]uuid.UUID, n)
+	for i := range batch {
+		batch[i] = storetest.NewNews("author", fmt.Sprintf("tag-%d", i%benchTags))
+		ids[i] = batch[i].ID
+	}
+	results, err := s.CreateBatch(context.Background(), batch, true)
+	if err != nil {
+		b.Fatalf("CreateBatch: %v", err)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			b.Fatalf("CreateBatch: %v", r.Err)
+		}
+	}
+	return s, ids
+}
+
+func BenchmarkGet(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			s, ids := filled(b, n)
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.Get(ctx, ids[i%n], false); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkQueryByTag(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			s, _ := filled(b, n)
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				q := memstore.Query{TagsAny: []string{fmt.Sprintf("tag-%d", i%benchTags)}, Limit: 20}
+				if _, _, err := s.Query(ctx, q); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package memstore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Sentinel errors returned by NewsStorer implementations. Backends wrap them
+// with context, so match them with errors.Is.
+var (
+	// ErrNotFound means no news or API key matches the id.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists means a news or API key with the id is already stored.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrConflict means the change does not apply to the current state of
+	// the record, e.g. restoring news that is not deleted.
+	ErrConflict = errors.New("conflicting state")
+	// ErrBatchAborted fails the valid items of an all-or-nothing batch that
+	// was abandoned because of another item.
+	ErrBatchAborted = errors.New("batch aborted")
+)
+
+func notFound(id uuid.UUID) error {
+	return fmt.Errorf("news %w: %s", ErrNotFound, id)
+}
+
+func alreadyExists(id uuid.UUID) error {
+	return fmt.Errorf("news %w: %s", ErrAlreadyExists, id)
+}
+
+func apiKeyNotFound(id uuid.UUID) error {
+	return fmt.Errorf("api key %w: %s", ErrNotFound, id)
+}
@@ -0,0 +1,134 @@
+package memstore
+
+import (
+	"bytes"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// index holds every stored news, deleted or not, keyed by id, together with
+// secondary indexes that are kept in step on every write. Callers must hold
+// the store lock.
+type index struct {
+	byID     map[uuid.UUID]*News
+	byAuthor map[string]map[uuid.UUID]*News
+	byTag    map[string]map[uuid.UUID]*News
+	// byCreated is ordered by CreatedAt, ties broken by ID.
+	byCreated []*News
+
+	// deleted counts soft deleted news and liveTags the live news per tag,
+	// for Stats.
+	deleted  int
+	liveTags map[string]int
+}
+
+func newIndex() index {
+	return index{
+		byID:     make(map[uuid.UUID]*News),
+		byAuthor: make(map[string]map[uuid.UUID]*News),
+		byTag:    make(map[string]map[uuid.UUID]*News),
+		liveTags: make(map[string]int),
+	}
+}
+
+func (ix *index) len() int {
+	return len(ix.byID)
+}
+
+func (ix *index) get(id uuid.UUID) *News {
+	return ix.byID[id]
+}
+
+// put inserts the news, replacing any stored news with the same id.
+func (ix *index) put(news *News) {
+	ix.remove(news.ID)
+
+	ix.byID[news.ID] = news
+	addToSet(ix.byAuthor, news.Author, news)
+	for _, tag := range news.Tags {
+		addToSet(ix.byTag, tag, news)
+	}
+	i, _ := slices.BinarySearchFunc(ix.byCreated, news, compareCreated)
+	ix.byCreated = slices.Insert(ix.byCreated, i, news)
+
+	if !news.DeletedAt.IsZero() {
+		ix.deleted++
+		return
+	}
+	for _, tag := range news.Tags {
+		ix.liveTags[tag]++
+	}
+}
+
+// remove drops the news with the id, if any.
+func (ix *index) remove(id uuid.UUID) {
+	news, ok := ix.byID[id]
+	if !ok {
+		return
+	}
+
+	delete(ix.byID, id)
+	removeFromSet(ix.byAuthor, news.Author, id)
+	for _, tag := range news.Tags {
+		removeFromSet(ix.byTag, tag, id)
+	}
+	if i, found := slices.BinarySearchFunc(ix.byCreated, news, compareCreated); found {
+		ix.byCreated = slices.Delete(ix.byCreated, i, i+1)
+	}
+
+	if !news.DeletedAt.IsZero() {
+		ix.deleted--
+		return
+	}
+	for _, tag := range news.Tags {
+		if ix.liveTags[tag]--; ix.liveTags[tag] == 0 {
+			delete(ix.liveTags, tag)
+		}
+	}
+}
+
+// created returns the news created in [start, end) in creation order. Zero
+// bounds are open. The result aliases the index and must not be modified.
+func (ix *index) created(start, end time.Time) []*News {
+	lo, hi := 0, len(ix.byCreated)
+	if !start.IsZero() {
+		lo, _ = slices.BinarySearchFunc(ix.byCreated, start, func(news *News, t time.Time) int {
+			return news.CreatedAt.Compare(t)
+		})
+	}
+	if !end.IsZero() {
+		hi, _ = slices.BinarySearchFunc(ix.byCreated, end, func(news *News, t time.Time) int {
+			return news.CreatedAt.Compare(t)
+		})
+	}
+	if lo > hi {
+		return nil
+	}
+	return ix.byCreated[lo:hi]
+}
+
+func compareCreated(a, b *News) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+func addToSet(sets map[string]map[uuid.UUID]*News, key string, news *News) {
+	set, ok := sets[key]
+	if !ok {
+		set = make(map[uuid.UUID]*News)
+		sets[key] = set
+	}
+	set[news.ID] = news
+}
+
+func removeFromSet(sets map[string]map[uuid.UUID]*News, key string, id uuid.UUID) {
+	set := sets[key]
+	delete(set, id)
+	if len(set) == 0 {
+		delete(sets, key)
+	}
+}
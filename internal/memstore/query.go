@@ -0,0 +1,262 @@
+package memstore
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderBy selects the sort key of a Query.
+type OrderBy int
+
+const (
+	OrderByCreatedAt OrderBy = iota
+	OrderByUpdatedAt
+	OrderByTitle
+)
+
+// Cursor marks the last news of the previous page. Only the field matching
+// the query order is used, ties are broken by ID.
+type Cursor struct {
+	Time  time.Time
+	Title string
+	ID    uuid.UUID
+}
+
+// CursorFor returns the cursor positioned on news for the given order.
+func CursorFor(news *News, order OrderBy) Cursor {
+	c := Cursor{Title: news.Title, ID: news.ID}
+	switch order {
+	case OrderByUpdatedAt:
+		c.Time = news.UpdatedAt
+	case OrderByCreatedAt, OrderByTitle:
+		c.Time = news.CreatedAt
+	}
+	return c
+}
+
+// Query filters and pages through the store. Zero values disable a filter.
+type Query struct {
+	Author string
+	// TagsAny matches news carrying at least one of the tags.
+	TagsAny []string
+	// TagsAll matches news carrying every one of the tags.
+	TagsAll []string
+	// SourceHost matches the host of the source URL, case-insensitively.
+	SourceHost string
+	// CreatedStart is inclusive, CreatedEnd is exclusive.
+	CreatedStart, CreatedEnd time.Time
+	IncludeDeleted           bool
+
+	OrderBy    OrderBy
+	Descending bool
+	// After resumes the listing behind the cursor. Because pages are keyed on
+	// the sort key rather than an offset, inserts between calls never shift
+	// or repeat items.
+	After *Cursor
+	Limit int
+}
+
+// queryCheckEvery is how many items Query scans between context checks.
+const queryCheckEvery = 1024
+
+// Query returns up to q.Limit matching news in order and reports whether
+// more are available. Candidates come from the most selective index for the
+// filters, and only the requested page is buffered, not the whole store.
+func (s *Store) Query(ctx context.Context, q Query) ([]*News, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	if q.Limit <= 0 {
+		return nil, false, nil
+	}
+
+	var after *News
+	if q.After != nil {
+		after = &News{ID: q.After.ID, Title: q.After.Title, CreatedAt: q.After.Time, UpdatedAt: q.After.Time}
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if q.OrderBy == OrderByCreatedAt && q.Author == "" && len(q.TagsAny) == 0 && len(q.TagsAll) == 0 {
+		return s.queryCreated(ctx, &q, after)
+	}
+
+	// Keep the q.Limit+1 smallest matches; the extra one tells us whether
+	// there is a next page.
+	page := &newsHeap{cmp: q.compare}
+	i := 0
+	for news := range s.candidates(&q) {
+		if i++; i%queryCheckEvery == 0 && ctx.Err() != nil {
+			return nil, false, ctx.Err()
+		}
+		if !q.matches(news) || (after != nil && q.compare(news, after) <= 0) {
+			continue
+		}
+		if page.Len() <= q.Limit {
+			heap.Push(page, news)
+		} else if q.compare(news, page.items[0]) < 0 {
+			page.items[0] = news
+			heap.Fix(page, 0)
+		}
+	}
+
+	result := page.items
+	slices.SortFunc(result, q.compare)
+	if len(result) > q.Limit {
+		return result[:q.Limit], true, nil
+	}
+	return result, false, nil
+}
+
+// queryCreated walks the created_at index in query order from the cursor,
+// so it stops as soon as the page is full. Callers must hold the read lock.
+func (s *Store) queryCreated(ctx context.Context, q *Query, after *News) ([]*News, bool, error) {
+	span := s.news.created(q.CreatedStart, q.CreatedEnd)
+	if after != nil {
+		i, found := slices.BinarySearchFunc(span, after, compareCreated)
+		if q.Descending {
+			span = span[:i]
+		} else {
+			if found {
+				i++
+			}
+			span = span[i:]
+		}
+	}
+
+	result := make([]*News, 0, min(q.Limit+1, len(span)))
+	for i := range span {
+		if i%queryCheckEvery == queryCheckEvery-1 && ctx.Err() != nil {
+			return nil, false, ctx.Err()
+		}
+		news := span[i]
+		if q.Descending {
+			news = span[len(span)-1-i]
+		}
+		if !q.matches(news) {
+			continue
+		}
+		if len(result) == q.Limit {
+			return result, true, nil
+		}
+		result = append(result, news)
+	}
+	return result, false, nil
+}
+
+// candidates yields a superset of the news matching q, taken from whichever
+// of the author, tag and created_at indexes holds the fewest entries.
+// Callers must hold the read lock.
+func (s *Store) candidates(q *Query) iter.Seq[*News] {
+	span := s.news.created(q.CreatedStart, q.CreatedEnd)
+	var set map[uuid.UUID]*News
+	size := len(span)
+	consider := func(candidate map[uuid.UUID]*News) {
+		if len(candidate) < size {
+			set, size = candidate, len(candidate)
+		}
+	}
+	if q.Author != "" {
+		consider(s.news.byAuthor[q.Author])
+	}
+	for _, tag := range q.TagsAll {
+		consider(s.news.byTag[tag])
+	}
+
+	if len(q.TagsAny) > 0 {
+		union := 0
+		for _, tag := range q.TagsAny {
+			union += len(s.news.byTag[tag])
+		}
+		if union < size {
+			return func(yield func(*News) bool) {
+				for i, tag := range q.TagsAny {
+					for _, news := range s.news.byTag[tag] {
+						// Yield news carrying several of the tags only once.
+						if slices.ContainsFunc(q.TagsAny[:i], func(seen string) bool { return slices.Contains(news.Tags, seen) }) {
+							continue
+						}
+						if !yield(news) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if set != nil || size < len(span) {
+		return maps.Values(set)
+	}
+	return slices.Values(span)
+}
+
+func (q *Query) matches(news *News) bool {
+	if !q.IncludeDeleted && !news.DeletedAt.IsZero() {
+		return false
+	}
+	if q.Author != "" && news.Author != q.Author {
+		return false
+	}
+	if q.SourceHost != "" && (news.Source == nil || !strings.EqualFold(news.Source.Hostname(), q.SourceHost)) {
+		return false
+	}
+	if !q.CreatedStart.IsZero() && news.CreatedAt.Before(q.CreatedStart) {
+		return false
+	}
+	if !q.CreatedEnd.IsZero() && !news.CreatedAt.Before(q.CreatedEnd) {
+		return false
+	}
+	if len(q.TagsAny) > 0 && !slices.ContainsFunc(q.TagsAny, func(tag string) bool { return slices.Contains(news.Tags, tag) }) {
+		return false
+	}
+	for _, tag := range q.TagsAll {
+		if !slices.Contains(news.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *Query) compare(a, b *News) int {
+	var c int
+	switch q.OrderBy {
+	case OrderByCreatedAt:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case OrderByUpdatedAt:
+		c = a.UpdatedAt.Compare(b.UpdatedAt)
+	case OrderByTitle:
+		c = strings.Compare(a.Title, b.Title)
+	}
+	if c == 0 {
+		c = bytes.Compare(a.ID[:], b.ID[:])
+	}
+	if q.Descending {
+		return -c
+	}
+	return c
+}
+
+// newsHeap is a max-heap on cmp, so the root is the first item to evict.
+type newsHeap struct {
+	items []*News
+	cmp   func(a, b *News) int
+}
+
+func (h *newsHeap) Len() int           { return len(h.items) }
+func (h *newsHeap) Less(i, j int) bool { return h.cmp(h.items[i], h.items[j]) > 0 }
+func (h *newsHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *newsHeap) Push(x any)         { h.items = append(h.items, x.(*News)) }
+func (h *newsHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
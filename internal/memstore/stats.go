@@ -0,0 +1,30 @@
+package memstore
+
+import "context"
+
+// Stats summarizes what the store holds.
+type Stats struct {
+	// News counts live news, Deleted soft deleted news.
+	News, Deleted int
+	// Tags counts the distinct tags of live news.
+	Tags int
+	// APIKeys counts keys, revoked or not.
+	APIKeys int
+}
+
+// Stats returns the current counts. It is cheap enough to call on every
+// metrics scrape.
+func (s *Store) Stats(ctx context.Context) (Stats, error) {
+	if err := ctx.Err(); err != nil {
+		return Stats{}, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return Stats{
+		News:    s.news.len() - s.news.deleted,
+		Deleted: s.news.deleted,
+		Tags:    len(s.news.liveTags),
+		APIKeys: len(s.apiKeys),
+	}, nil
+}
@@ -1,13 +1,27 @@
 package memstore
 
 import (
+	"context"
+	"fmt"
+	"maps"
 	"net/url"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Updatable field names accepted by Store.Update.
+const (
+	FieldAuthor  = "author"
+	FieldTitle   = "title"
+	FieldSummary = "summary"
+	FieldContent = "content"
+	FieldSource  = "source"
+	FieldTags    = "tags"
+)
+
 type News struct {
 	ID                              uuid.UUID
 	Author, Title, Summary, Content string
@@ -16,20 +30,158 @@ type News struct {
 	CreatedAt, UpdatedAt, DeletedAt time.Time
 }
 
+// Change is a mutation about to be committed to the store. It carries
+// either News or an APIKey.
+type Change struct {
+	Type   EventType
+	News   *News
+	APIKey *APIKey
+}
+
+// CommitHook is called with every change before it becomes visible, while
+// the store's write lock is held. Returning an error abandons the change.
+// Changes passed in one call must be persisted atomically.
+type CommitHook func(ctx context.Context, changes ...Change) error
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithCommitHook installs a hook that sees every change before it is
+// committed, e.g. to write it ahead to a log.
+func WithCommitHook(hook CommitHook) Option {
+	return func(s *Store) {
+		s.hook = hook
+	}
+}
+
 type Store struct {
-	lock sync.RWMutex
-	news []*News
+	lock    sync.RWMutex
+	news    index
+	apiKeys map[uuid.UUID]*APIKey
+	hook    CommitHook
+
+	// Change feed for watchers, see watch.go. The epoch ties resume tokens
+	// to this instance, since sequence numbers restart with the store.
+	epoch   uuid.UUID
+	seq     uint64
+	history []Event
+	subs    map[*Subscription]struct{}
+}
+
+func New(opts ...Option) *Store {
+	s := &Store{
+		news:    newIndex(),
+		apiKeys: make(map[uuid.UUID]*APIKey),
+		lock:    sync.RWMutex{},
+		epoch:   uuid.New(),
+		subs:    make(map[*Subscription]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func New() *Store {
-	return &Store{
-		news: make([]*News, 0),
-		lock: sync.RWMutex{},
+// Create stores the news. If the id is already taken it returns
+// ErrAlreadyExists, unless the stored news is live and has the same content,
+// in which case the create is treated as a retry and the stored news is
+// returned.
+func (s *Store) Create(ctx context.Context, news *News) (*News, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	createdNews := newNews(news)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if existing := s.news.get(news.ID); existing != nil {
+		if existing.DeletedAt.IsZero() && sameContent(existing, news) {
+			return existing, nil
+		}
+		return nil, alreadyExists(news.ID)
+	}
+	if err := s.commit(ctx, Change{Type: EventCreated, News: createdNews}); err != nil {
+		return nil, err
 	}
+	s.news.put(createdNews)
+	s.publish(EventCreated, createdNews)
+	return createdNews, nil
+}
+
+// BatchResult is the outcome of one item of CreateBatch.
+type BatchResult struct {
+	News *News
+	Err  error
 }
 
-func (s *Store) Create(news *News) *News {
-	createdNews := &News{
+// CreateBatch stores the batch under a single lock, so readers and watchers
+// never observe a partially applied batch. Each item follows the rules of
+// Create, and an id repeated within the batch fails with ErrAlreadyExists.
+// With allOrNothing, one failed item stores nothing and the other items fail
+// with ErrBatchAborted. The returned error is for the batch as a whole.
+func (s *Store) CreateBatch(ctx context.Context, batch []*News, allOrNothing bool) ([]BatchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	results := make([]BatchResult, len(batch))
+	changes := make([]Change, 0, len(batch))
+	seen := make(map[uuid.UUID]bool, len(batch))
+	failed := false
+	for i, news := range batch {
+		existing := s.news.get(news.ID)
+		switch {
+		case seen[news.ID]:
+			results[i].Err = alreadyExists(news.ID)
+		case existing != nil && existing.DeletedAt.IsZero() && sameContent(existing, news):
+			results[i].News = existing
+		case existing != nil:
+			results[i].Err = alreadyExists(news.ID)
+		default:
+			results[i].News = newNews(news)
+			changes = append(changes, Change{Type: EventCreated, News: results[i].News})
+		}
+		seen[news.ID] = true
+		failed = failed || results[i].Err != nil
+	}
+
+	if allOrNothing && failed {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = BatchResult{Err: ErrBatchAborted}
+			}
+		}
+		return results, nil
+	}
+
+	if len(changes) == 0 {
+		return results, nil
+	}
+	if err := s.commit(ctx, changes...); err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		s.news.put(change.News)
+		s.publish(EventCreated, change.News)
+	}
+	return results, nil
+}
+
+// sameContent reports whether a and b carry the same user-supplied fields.
+func sameContent(a, b *News) bool {
+	return a.Author == b.Author &&
+		a.Title == b.Title &&
+		a.Summary == b.Summary &&
+		a.Content == b.Content &&
+		a.Source.String() == b.Source.String() &&
+		slices.Equal(a.Tags, b.Tags)
+}
+
+func newNews(news *News) *News {
+	return &News{
 		ID:        news.ID,
 		Author:    news.Author,
 		Title:     news.Title,
@@ -40,37 +192,186 @@ func (s *Store) Create(news *News) *News {
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 	}
+}
 
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	s.news = append(s.news, createdNews)
-	return createdNews
+// Get returns the news with the given id, or ErrNotFound. Soft deleted news
+// is only returned when includeDeleted is set.
+func (s *Store) Get(ctx context.Context, id uuid.UUID, includeDeleted bool) (*News, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if news := s.news.get(id); news != nil && (includeDeleted || news.DeletedAt.IsZero()) {
+		return news, nil
+	}
+	return nil, notFound(id)
 }
 
-func (s *Store) Get(id uuid.UUID) *News {
+// GetAll returns all stored news in creation order. Soft deleted news is
+// only returned when includeDeleted is set.
+func (s *Store) GetAll(ctx context.Context, includeDeleted bool) ([]*News, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	s.lock.RLock()
 	defer s.lock.RUnlock()
-	for _, news := range s.news {
-		if news.ID == id && news.DeletedAt.IsZero() {
-			return news
+	all := make([]*News, 0, s.news.len())
+	for _, news := range s.news.byCreated {
+		if includeDeleted || news.DeletedAt.IsZero() {
+			all = append(all, news)
+		}
+	}
+	return all, nil
+}
+
+// Load applies changes as-is, bypassing the commit hook and watchers. It is
+// meant for rebuilding the store from persisted state.
+func (s *Store) Load(changes ...Change) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, change := range changes {
+		if change.APIKey != nil {
+			s.apiKeys[change.APIKey.ID] = change.APIKey
+			continue
+		}
+		if change.Type == EventPurged {
+			s.news.remove(change.News.ID)
+		} else {
+			s.news.put(change.News)
 		}
 	}
-	return nil
 }
 
-func (s *Store) GetAll() []*News {
+// Snapshot calls fn with every stored news, deleted or not, in creation
+// order and every API key, under the read lock. No commit can run until fn
+// returns.
+func (s *Store) Snapshot(fn func(all []*News, apiKeys []*APIKey)) {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
-	return s.news
+	fn(slices.Clone(s.news.byCreated), slices.Collect(maps.Values(s.apiKeys)))
+}
+
+func (s *Store) commit(ctx context.Context, changes ...Change) error {
+	if s.hook == nil {
+		return nil
+	}
+	return s.hook(ctx, changes...)
 }
 
-func (s *Store) Delete(id uuid.UUID) {
+// Update overwrites the named fields of the stored news with the values from
+// news and bumps UpdatedAt. It returns ErrNotFound when no live news has
+// news.ID.
+func (s *Store) Update(ctx context.Context, news *News, fields []string) (*News, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	for _, news := range s.news {
-		if news.ID == id {
-			//Set zero to timestamp
-			news.DeletedAt = time.Time{}
+	existing := s.news.get(news.ID)
+	if existing == nil || !existing.DeletedAt.IsZero() {
+		return nil, notFound(news.ID)
+	}
+
+	// Copy on write so readers holding the old pointer never see a partial update.
+	updated := *existing
+	for _, field := range fields {
+		switch field {
+		case FieldAuthor:
+			updated.Author = news.Author
+		case FieldTitle:
+			updated.Title = news.Title
+		case FieldSummary:
+			updated.Summary = news.Summary
+		case FieldContent:
+			updated.Content = news.Content
+		case FieldSource:
+			updated.Source = news.Source
+		case FieldTags:
+			updated.Tags = news.Tags
 		}
 	}
+	updated.UpdatedAt = time.Now().UTC()
+	if err := s.commit(ctx, Change{Type: EventUpdated, News: &updated}); err != nil {
+		return nil, err
+	}
+	s.news.put(&updated)
+	s.publish(EventUpdated, &updated)
+	return &updated, nil
+}
+
+// Delete soft deletes the news by setting DeletedAt and returns the
+// tombstone. It returns ErrNotFound when no live news has the id.
+func (s *Store) Delete(ctx context.Context, id uuid.UUID) (*News, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	existing := s.news.get(id)
+	if existing == nil || !existing.DeletedAt.IsZero() {
+		return nil, notFound(id)
+	}
+
+	deleted := *existing
+	deleted.DeletedAt = time.Now().UTC()
+	if err := s.commit(ctx, Change{Type: EventDeleted, News: &deleted}); err != nil {
+		return nil, err
+	}
+	s.news.put(&deleted)
+	s.publish(EventDeleted, &deleted)
+	return &deleted, nil
+}
+
+// Restore clears DeletedAt on a soft deleted news and returns it. It returns
+// ErrNotFound for an unknown id and ErrConflict if the news is not deleted.
+func (s *Store) Restore(ctx context.Context, id uuid.UUID) (*News, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	existing := s.news.get(id)
+	if existing == nil {
+		return nil, notFound(id)
+	}
+	if existing.DeletedAt.IsZero() {
+		return nil, fmt.Errorf("%w: news %s is not deleted", ErrConflict, id)
+	}
+
+	restored := *existing
+	restored.DeletedAt = time.Time{}
+	restored.UpdatedAt = time.Now().UTC()
+	if err := s.commit(ctx, Change{Type: EventRestored, News: &restored}); err != nil {
+		return nil, err
+	}
+	s.news.put(&restored)
+	s.publish(EventRestored, &restored)
+	return &restored, nil
+}
+
+// Purge permanently removes the news, deleted or not. It returns ErrNotFound
+// for an unknown id.
+func (s *Store) Purge(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	existing := s.news.get(id)
+	if existing == nil {
+		return notFound(id)
+	}
+	if err := s.commit(ctx, Change{Type: EventPurged, News: existing}); err != nil {
+		return err
+	}
+	s.news.remove(id)
+	s.publish(EventPurged, existing)
+	return nil
 }
@@ -0,0 +1,15 @@
+package memstore_test
+
+import (
+	"testing"
+
+	ingrpc "github.com/sabuhigr/grpc-demo/internal/grpc"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+	"github.com/sabuhigr/grpc-demo/internal/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) ingrpc.NewsStorer {
+		return memstore.New()
+	})
+}
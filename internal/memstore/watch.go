@@ -0,0 +1,202 @@
+package memstore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// watchHistory is how many recent events are kept for resuming watchers.
+	watchHistory = 1024
+	// watchBuffer is how many undelivered events a watcher may fall behind
+	// before it is disconnected.
+	watchBuffer = 64
+)
+
+var (
+	// ErrSlowConsumer ends a subscription whose buffer overflowed. The
+	// watcher may resume from the last event it received.
+	ErrSlowConsumer = errors.New("watcher fell too far behind")
+	// ErrResumeTokenExpired means the events after the token have already
+	// left the retained history, or the token is from another store instance.
+	ErrResumeTokenExpired = errors.New("resume token is no longer valid")
+	// ErrInvalidResumeToken means the token could not be decoded.
+	ErrInvalidResumeToken = errors.New("resume token is malformed")
+)
+
+type EventType int
+
+const (
+	EventCreated EventType = iota + 1
+	EventUpdated
+	EventDeleted
+	EventRestored
+	EventPurged
+)
+
+// Event describes a single change to the store.
+type Event struct {
+	Type EventType
+	News *News
+	Time time.Time
+	// ResumeToken resumes a watch right after this event.
+	ResumeToken string
+
+	seq uint64
+}
+
+// WatchFilter selects the events a subscription receives. Zero values
+// match everything.
+type WatchFilter struct {
+	Author string
+	// Tags matches news carrying at least one of the tags.
+	Tags []string
+}
+
+func (f WatchFilter) matches(news *News) bool {
+	if f.Author != "" && news.Author != f.Author {
+		return false
+	}
+	if len(f.Tags) > 0 && !slices.ContainsFunc(f.Tags, func(tag string) bool { return slices.Contains(news.Tags, tag) }) {
+		return false
+	}
+	return true
+}
+
+// Subscription delivers change events until it is closed, either by the
+// watcher or by the store when the watcher falls behind.
+type Subscription struct {
+	store  *Store
+	filter WatchFilter
+	events chan Event
+	err    error
+}
+
+// Events returns the event channel. It is closed when the subscription ends.
+func (sub *Subscription) Events() <-chan Event {
+	return sub.events
+}
+
+// Err returns why the store ended the subscription, once Events is closed.
+func (sub *Subscription) Err() error {
+	sub.store.lock.RLock()
+	defer sub.store.lock.RUnlock()
+	return sub.err
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (sub *Subscription) Close() {
+	sub.store.lock.Lock()
+	defer sub.store.lock.Unlock()
+	sub.store.unsubscribe(sub, nil)
+}
+
+// Watch subscribes to changes matching filter. With an empty resumeToken the
+// subscription starts at the next change; otherwise the retained events after
+// the token are replayed first.
+func (s *Store) Watch(ctx context.Context, filter WatchFilter, resumeToken string) (*Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var backlog []Event
+	if resumeToken != "" {
+		epoch, seq, err := decodeResumeToken(resumeToken)
+		if err != nil {
+			return nil, err
+		}
+		if epoch != s.epoch || seq > s.seq {
+			return nil, ErrResumeTokenExpired
+		}
+		// The event right after seq must still be retained, unless nothing
+		// has happened since.
+		if seq < s.seq && (len(s.history) == 0 || s.history[0].seq > seq+1) {
+			return nil, ErrResumeTokenExpired
+		}
+		for _, event := range s.history {
+			if event.seq > seq && filter.matches(event.News) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	sub := &Subscription{
+		store:  s,
+		filter: filter,
+		events: make(chan Event, len(backlog)+watchBuffer),
+	}
+	for _, event := range backlog {
+		sub.events <- event
+	}
+	s.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// publish records the change and fans it out. Callers must hold the write lock.
+func (s *Store) publish(eventType EventType, news *News) {
+	s.seq++
+	event := Event{
+		Type:        eventType,
+		News:        news,
+		Time:        time.Now().UTC(),
+		ResumeToken: encodeResumeToken(s.epoch, s.seq),
+		seq:         s.seq,
+	}
+
+	if len(s.history) == watchHistory {
+		s.history = slices.Delete(s.history, 0, 1)
+	}
+	s.history = append(s.history, event)
+
+	for sub := range s.subs {
+		if !sub.filter.matches(news) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// Never let one slow watcher block writers; cut it off and let
+			// it resume from its last token.
+			s.unsubscribe(sub, ErrSlowConsumer)
+		}
+	}
+}
+
+// unsubscribe closes the subscription. Callers must hold the write lock.
+func (s *Store) unsubscribe(sub *Subscription, err error) {
+	if _, ok := s.subs[sub]; !ok {
+		return
+	}
+	delete(s.subs, sub)
+	sub.err = err
+	close(sub.events)
+}
+
+func encodeResumeToken(epoch uuid.UUID, seq uint64) string {
+	raw := make([]byte, 0, len(epoch)+8)
+	raw = append(raw, epoch[:]...)
+	raw = binary.BigEndian.AppendUint64(raw, seq)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeResumeToken(token string) (uuid.UUID, uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != len(uuid.UUID{})+8 {
+		return uuid.UUID{}, 0, ErrInvalidResumeToken
+	}
+
+	epoch, err := uuid.FromBytes(raw[:len(uuid.UUID{})])
+	if err != nil {
+		return uuid.UUID{}, 0, ErrInvalidResumeToken
+	}
+	return epoch, binary.BigEndian.Uint64(raw[len(uuid.UUID{}):]), nil
+}
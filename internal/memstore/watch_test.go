@@ -0,0 +1,136 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// publishN creates n news and returns the events a watcher saw for them.
+func publishN(t *testing.T, s *Store, n int) []Event {
+	t.Helper()
+	sub, err := s.Watch(context.Background(), WatchFilter{}, "")
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer sub.Close()
+
+	events := make([]Event, 0, n)
+	for range n {
+		id := uuid.New()
+		if _, err := s.Create(context.Background(), &News{
+			ID:      id,
+			Author:  "alice",
+			Title:   "title " + id.String(),
+			Summary: "summary",
+			Content: "content",
+			Source:  &url.URL{Scheme: "https", Host: "example.com"},
+			Tags:    []string{"go"},
+		}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		events = append(events, <-sub.Events())
+	}
+	return events
+}
+
+// drain returns the events buffered in sub and why it ended, if it did.
+func drain(sub *Subscription) ([]Event, bool) {
+	var events []Event
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return events, true
+			}
+			events = append(events, event)
+		default:
+			return events, false
+		}
+	}
+}
+
+func TestWatchResume(t *testing.T) {
+	s := New()
+	events := publishN(t, s, watchHistory+10)
+	// The first 10 events have left the history.
+	oldest := 10
+
+	tests := []struct {
+		name       string
+		token      string
+		wantEvents int
+		wantErr    error
+	}{
+		{"inside the history", events[500].ResumeToken, len(events) - 501, nil},
+		{"right before the oldest retained event", events[oldest-1].ResumeToken, watchHistory, nil},
+		{"latest event", events[len(events)-1].ResumeToken, 0, nil},
+		{"fell out of the history", events[oldest-2].ResumeToken, 0, ErrResumeTokenExpired},
+		{"old epoch", encodeResumeToken(uuid.New(), uint64(len(events))), 0, ErrResumeTokenExpired},
+		{"from the future", encodeResumeToken(s.epoch, uint64(len(events)+1)), 0, ErrResumeTokenExpired},
+		{"malformed", "not a token", 0, ErrInvalidResumeToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := s.Watch(context.Background(), WatchFilter{}, tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Watch: %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer sub.Close()
+
+			replayed, _ := drain(sub)
+			if len(replayed) != tt.wantEvents {
+				t.Fatalf("replayed %d events, want %d", len(replayed), tt.wantEvents)
+			}
+			for i, event := range replayed {
+				if want := events[len(events)-tt.wantEvents+i]; event.ResumeToken != want.ResumeToken {
+					t.Fatalf("replayed event %d is out of order", i)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchDropsSlowConsumer(t *testing.T) {
+	s := New()
+	sub, err := s.Watch(context.Background(), WatchFilter{}, "")
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer sub.Close()
+
+	// A full buffer is fine; one event more cuts the watcher off.
+	publishN(t, s, watchBuffer)
+	if _, ended := drain(sub); ended {
+		t.Fatalf("watcher with a full buffer was dropped: %v", sub.Err())
+	}
+	missed := publishN(t, s, watchBuffer+1)
+
+	received, ended := drain(sub)
+	if !ended {
+		t.Fatal("watcher past its buffer is still subscribed")
+	}
+	if !errors.Is(sub.Err(), ErrSlowConsumer) {
+		t.Errorf("Err() = %v, want ErrSlowConsumer", sub.Err())
+	}
+	if len(received) != watchBuffer {
+		t.Fatalf("received %d events before the drop, want %d", len(received), watchBuffer)
+	}
+
+	// The watcher picks up where it was cut off.
+	resumed, err := s.Watch(context.Background(), WatchFilter{}, received[len(received)-1].ResumeToken)
+	if err != nil {
+		t.Fatalf("Watch from the last received event: %v", err)
+	}
+	defer resumed.Close()
+	replayed, _ := drain(resumed)
+	if len(replayed) != 1 || replayed[0].ResumeToken != missed[watchBuffer].ResumeToken {
+		t.Errorf("resume replayed %d events, want the one missed", len(replayed))
+	}
+}
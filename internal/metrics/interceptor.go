@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Unary returns the interceptor for unary calls. It should come first in
+// the chain so rejected calls are counted and timed as well.
+func (m *Metrics) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		labels := methodLabels("unary", info.FullMethod)
+		m.started.With(labels).Inc()
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		m.done(labels, start, err)
+		return resp, err
+	}
+}
+
+// Stream returns the interceptor for streaming calls.
+func (m *Metrics) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		labels := methodLabels(streamType(info), info.FullMethod)
+		m.started.With(labels).Inc()
+		inFlight := m.inFlight.With(labels)
+		inFlight.Inc()
+		defer inFlight.Dec()
+		start := time.Now()
+
+		err := handler(srv, &countingStream{
+			ServerStream: ss,
+			received:     m.msgReceived.With(labels),
+			sent:         m.msgSent.With(labels),
+		})
+
+		m.done(labels, start, err)
+		return err
+	}
+}
+
+func (m *Metrics) done(labels prometheus.Labels, start time.Time, err error) {
+	m.handling.With(labels).Observe(time.Since(start).Seconds())
+	handled := prometheus.Labels{"grpc_code": status.Code(err).String()}
+	for k, v := range labels {
+		handled[k] = v
+	}
+	m.handled.With(handled).Inc()
+}
+
+func methodLabels(typ, fullMethod string) prometheus.Labels {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return prometheus.Labels{"grpc_type": typ, "grpc_service": service, "grpc_method": method}
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
+
+// countingStream counts the messages of a stream.
+type countingStream struct {
+	grpc.ServerStream
+	received, sent prometheus.Counter
+}
+
+func (s *countingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.received.Inc()
+	return nil
+}
+
+func (s *countingStream) SendMsg(m any) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.sent.Inc()
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package metrics exports Prometheus metrics about RPCs and the store.
+package metrics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// rpcLabels identify a method. grpc_type is unary, client_stream,
+// server_stream or bidi_stream.
+var rpcLabels = []string{"grpc_type", "grpc_service", "grpc_method"}
+
+// Metrics holds the collectors of one server. Each Metrics has its own
+// registry, so several can coexist in a process.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	started     *prometheus.CounterVec
+	handled     *prometheus.CounterVec
+	handling    *prometheus.HistogramVec
+	inFlight    *prometheus.GaugeVec
+	msgReceived *prometheus.CounterVec
+	msgSent     *prometheus.CounterVec
+	panics      *prometheus.CounterVec
+}
+
+// New creates the RPC collectors, plus the Go runtime and process ones.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_started_total",
+			Help: "RPCs started on the server.",
+		}, rpcLabels),
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "RPCs completed on the server, by status code.",
+		}, append(rpcLabels, "grpc_code")),
+		handling: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Time from the start of an RPC until the server completed it.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, rpcLabels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "grpc_server_streams_in_flight",
+			Help: "Streaming RPCs currently open.",
+		}, rpcLabels),
+		msgReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_received_total",
+			Help: "Stream messages received from clients.",
+		}, rpcLabels),
+		msgSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_sent_total",
+			Help: "Stream messages sent to clients.",
+		}, rpcLabels),
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_panics_recovered_total",
+			Help: "Panics recovered while handling RPCs.",
+		}, []string{"grpc_service", "grpc_method"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.started, m.handled, m.handling, m.inFlight, m.msgReceived, m.msgSent, m.panics,
+	)
+	return m
+}
+
+// Panic counts a recovered panic of the full method.
+func (m *Metrics) Panic(fullMethod string) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	m.panics.WithLabelValues(service, method).Inc()
+}
+
+// Register adds collectors of other packages to the registry.
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+)
+
+// statsTimeout bounds how long a scrape waits for the store.
+const statsTimeout = 5 * time.Second
+
+// StatsFunc reports the contents of a store, like memstore.Store.Stats.
+type StatsFunc func(ctx context.Context) (memstore.Stats, error)
+
+var (
+	storeNewsDesc    = prometheus.NewDesc("news_store_news", "Live news in the store.", nil, nil)
+	storeDeletedDesc = prometheus.NewDesc("news_store_deleted_news", "Soft deleted news in the store.", nil, nil)
+	storeTagsDesc    = prometheus.NewDesc("news_store_tags", "Distinct tags of live news.", nil, nil)
+	storeAPIKeysDesc = prometheus.NewDesc("news_store_api_keys", "API keys in the store, revoked or not.", nil, nil)
+)
+
+// StoreCollector reads the store gauges on every scrape, so they are never
+// stale.
+type StoreCollector struct {
+	stats StatsFunc
+}
+
+// NewStoreCollector collects the stats reported by stats.
+func NewStoreCollector(stats StatsFunc) *StoreCollector {
+	return &StoreCollector{stats: stats}
+}
+
+func (c *StoreCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storeNewsDesc
+	ch <- storeDeletedDesc
+	ch <- storeTagsDesc
+	ch <- storeAPIKeysDesc
+}
+
+func (c *StoreCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+
+	stats, err := c.stats(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(storeNewsDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(storeNewsDesc, prometheus.GaugeValue, float64(stats.News))
+	ch <- prometheus.MustNewConstMetric(storeDeletedDesc, prometheus.GaugeValue, float64(stats.Deleted))
+	ch <- prometheus.MustNewConstMetric(storeTagsDesc, prometheus.GaugeValue, float64(stats.Tags))
+	ch <- prometheus.MustNewConstMetric(storeAPIKeysDesc, prometheus.GaugeValue, float64(stats.APIKeys))
+}
@@ -0,0 +1,130 @@
+// Package openapi serves the OpenAPI v3 document of the REST gateway and an
+// offline explorer for it. openapi.yaml is generated from the protos by
+// `make generate-proto`; do not edit it by hand.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	newsv1 "github.com/sabuhigr/grpc-demo/api/news/v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// ndjson is the content type of streamed requests and responses.
+const ndjson = "application/x-ndjson"
+
+//go:embed openapi.yaml
+var spec []byte
+
+//go:embed explorer.html
+var explorer []byte
+
+// Document returns the OpenAPI document as JSON. The generator describes
+// every method as plain JSON, so streaming methods are changed to NDJSON
+// here, and the bearer authentication of the gateway is declared.
+func Document() ([]byte, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi.yaml: %w", err)
+	}
+
+	streaming := streamingMethods(newsv1.File_news_v1_service_proto.Services())
+	paths, _ := doc["paths"].(map[string]any)
+	for _, path := range paths {
+		ops, _ := path.(map[string]any)
+		for _, op := range ops {
+			op, _ := op.(map[string]any)
+			if method, ok := streaming[fmt.Sprint(op["operationId"])]; ok {
+				streamContent(op, method)
+			}
+		}
+	}
+
+	components, _ := doc["components"].(map[string]any)
+	if components == nil {
+		components = make(map[string]any)
+		doc["components"] = components
+	}
+	components["securitySchemes"] = map[string]any{
+		"bearer": map[string]any{
+			"type":        "http",
+			"scheme":      "bearer",
+			"description": "A JWT, an API key or the static token of the server.",
+		},
+	}
+	doc["security"] = []any{map[string]any{"bearer": []any{}}}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// streamingMethods indexes the streaming methods of services by their
+// operation id, Service_Method.
+func streamingMethods(services protoreflect.ServiceDescriptors) map[string]protoreflect.MethodDescriptor {
+	methods := make(map[string]protoreflect.MethodDescriptor)
+	for i := 0; i < services.Len(); i++ {
+		service := services.Get(i)
+		for j := 0; j < service.Methods().Len(); j++ {
+			method := service.Methods().Get(j)
+			if method.IsStreamingClient() || method.IsStreamingServer() {
+				methods[string(service.Name())+"_"+string(method.Name())] = method
+			}
+		}
+	}
+	return methods
+}
+
+// streamContent describes the streamed sides of op as NDJSON: one message
+// per line for requests, and for responses one {"result": ...} object per
+// message and an {"error": ...} object if the stream fails.
+func streamContent(op map[string]any, method protoreflect.MethodDescriptor) {
+	if body, ok := op["requestBody"].(map[string]any); ok && method.IsStreamingClient() {
+		if content, ok := body["content"].(map[string]any); ok {
+			body["content"] = map[string]any{ndjson: content["application/json"]}
+			body["description"] = "One request message per line."
+		}
+	}
+	if !method.IsStreamingServer() {
+		return
+	}
+	responses, _ := op["responses"].(map[string]any)
+	success, _ := responses["200"].(map[string]any)
+	content, _ := success["content"].(map[string]any)
+	media, _ := content["application/json"].(map[string]any)
+	if media == nil {
+		return
+	}
+	success["content"] = map[string]any{ndjson: map[string]any{
+		"schema": map[string]any{
+			"type":        "object",
+			"description": "One object per line: a message, or the error that ended the stream.",
+			"properties": map[string]any{
+				"result": media["schema"],
+				"error":  map[string]any{"$ref": "#/components/schemas/Status"},
+			},
+		},
+	}}
+}
+
+// Register serves the document at /openapi.json and the explorer at /docs
+// on mux.
+func Register(mux *http.ServeMux) error {
+	doc, err := Document()
+	if err != nil {
+		return err
+	}
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	})
+	mux.HandleFunc("GET /docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		// The page is self-contained and only talks to this origin.
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+		_, _ = w.Write(explorer)
+	})
+	return nil
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CounterStore keeps quota counters. Implementations backed by a shared
+// store, e.g. Redis, let several servers enforce one quota.
+type CounterStore interface {
+	// Add adds delta to the counter and returns the new value. A counter
+	// past expiresAt starts again from zero.
+	Add(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error)
+}
+
+// MemoryCounters is a CounterStore local to the process.
+type MemoryCounters struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastSweep time.Time
+}
+
+type counter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// NewMemoryCounters creates an empty in-memory counter store.
+func NewMemoryCounters() *MemoryCounters {
+	return &MemoryCounters{
+		counters:  make(map[string]*counter),
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *MemoryCounters) Add(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) > time.Minute {
+		for k, c := range m.counters {
+			if now.After(c.expiresAt) {
+				delete(m.counters, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	c, ok := m.counters[key]
+	if !ok || now.After(c.expiresAt) {
+		c = &counter{expiresAt: expiresAt}
+		m.counters[key] = c
+	}
+	c.value += delta
+	return c.value, nil
+}
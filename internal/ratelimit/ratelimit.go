@@ -0,0 +1,335 @@
+// Package ratelimit throttles calls per principal, method and peer IP with
+// token buckets, and enforces daily quotas kept in a pluggable counter
+// store. Rejections carry QuotaFailure and RetryInfo details.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"github.com/sabuhigr/grpc-demo/internal/logging"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// idleBucketTTL is how long an unused bucket is kept. A bucket idle for this
+// long has refilled anyway, unless its rate is tiny.
+const idleBucketTTL = 10 * time.Minute
+
+// Limit is a token bucket: Burst calls at once, refilled at Rate per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimit parses "rate:burst", e.g. "5:10".
+func ParseLimit(s string) (Limit, error) {
+	rate, burst, ok := strings.Cut(s, ":")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q: want rate:burst", s)
+	}
+	l := Limit{}
+	var err error
+	if l.Rate, err = strconv.ParseFloat(rate, 64); err != nil || l.Rate <= 0 {
+		return Limit{}, fmt.Errorf("limit %q: rate must be a positive number", s)
+	}
+	if l.Burst, err = strconv.Atoi(burst); err != nil || l.Burst < 1 {
+		return Limit{}, fmt.Errorf("limit %q: burst must be a positive integer", s)
+	}
+	return l, nil
+}
+
+// ParseMethodLimits parses "method=rate:burst,..." into per-method limits.
+func ParseMethodLimits(s string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, spec := range strings.Split(s, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		method, limit, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("method limit %q: want method=rate:burst", spec)
+		}
+		l, err := ParseLimit(limit)
+		if err != nil {
+			return nil, err
+		}
+		limits[method] = l
+	}
+	return limits, nil
+}
+
+// Options configures a Limiter.
+type Options struct {
+	// Default applies to methods without an entry in Methods. A zero
+	// Default leaves them unlimited.
+	Default Limit
+	// Methods overrides the limit per full method name.
+	Methods map[string]Limit
+	// DailyQuotas caps how often each principal may call a method per UTC
+	// day. Failed calls do not count.
+	DailyQuotas map[string]int64
+	// ItemQuotas charges each item a streaming method stores to the daily
+	// quota of another method, e.g. BulkCreateNews items to CreateNews. The
+	// handler charges the items with ReserveItem.
+	ItemQuotas map[string]string
+	// Counters keeps the daily quota counts. Defaults to NewMemoryCounters.
+	Counters CounterStore
+}
+
+// Limiter enforces Options on every call.
+type Limiter struct {
+	opts atomic.Pointer[Options]
+	// now is the clock, replaced in tests.
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New creates a limiter.
+func New(opts Options) *Limiter {
+	if opts.Counters == nil {
+		opts.Counters = NewMemoryCounters()
+	}
+	l := &Limiter{
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+	l.opts.Store(&opts)
+	return l
+}
+
+// SetOptions replaces the limits of calls that start from now on. Buckets
+// and counts carry over; without Counters, the current store is kept.
+func (l *Limiter) SetOptions(opts Options) {
+	if opts.Counters == nil {
+		opts.Counters = l.opts.Load().Counters
+	}
+	l.opts.Store(&opts)
+}
+
+// Unary returns the interceptor for unary calls. It must run after
+// authentication to see the principal.
+func (l *Limiter) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := l.allow(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		release, err := l.reserveQuota(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		release = sync.OnceFunc(release)
+		resp, err := handler(context.WithValue(ctx, refundKey{}, release), req)
+		if err != nil {
+			release()
+		}
+		return resp, err
+	}
+}
+
+type refundKey struct{}
+
+// Refund gives back the daily quota charged for the current unary call, for
+// handlers that find the call changed nothing, e.g. a retried create. It
+// may be called more than once and does nothing outside the limiter.
+func Refund(ctx context.Context) {
+	if release, ok := ctx.Value(refundKey{}).(func()); ok {
+		release()
+	}
+}
+
+// Stream returns the interceptor for streaming calls. Only opening a stream
+// is limited, not the messages on it.
+func (l *Limiter) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.allow(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		release, err := l.reserveQuota(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		if method, ok := l.opts.Load().ItemQuotas[info.FullMethod]; ok {
+			ctx := context.WithValue(ss.Context(), itemQuotaKey{}, &itemQuota{limiter: l, method: method})
+			ss = &serverStream{ServerStream: ss, ctx: ctx}
+		}
+		if err := handler(srv, ss); err != nil {
+			release()
+			return err
+		}
+		return nil
+	}
+}
+
+// allow takes a token from the bucket of the caller.
+func (l *Limiter) allow(ctx context.Context, method string) error {
+	opts := l.opts.Load()
+	limit, ok := opts.Methods[method]
+	if !ok {
+		limit = opts.Default
+	}
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	subject, ip := subject(ctx), peerIP(ctx)
+	key := subject + "\x00" + method + "\x00" + ip
+	now := l.now()
+
+	l.mu.Lock()
+	if now.Sub(l.lastSweep) > idleBucketTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > idleBucketTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if allowed {
+		return nil
+	}
+	logging.FromContext(ctx).Debug("Rate limit exceeded")
+	return exhausted(
+		fmt.Sprintf("rate limit exceeded for %s, retry in %s", method, wait.Round(time.Millisecond)),
+		&errdetails.QuotaFailure_Violation{
+			Subject:     fmt.Sprintf("principal:%s method:%s ip:%s", subject, method, ip),
+			Description: fmt.Sprintf("at most %d calls at once, refilled at %g per second", limit.Burst, limit.Rate),
+		},
+		wait,
+	)
+}
+
+// reserveQuota counts the call against the caller's daily quota. The
+// returned func gives the call back if it fails.
+func (l *Limiter) reserveQuota(ctx context.Context, method string) (func(), error) {
+	opts := l.opts.Load()
+	quota, ok := opts.DailyQuotas[method]
+	if !ok {
+		return func() {}, nil
+	}
+
+	now := l.now().UTC()
+	day := now.Format(time.DateOnly)
+	tomorrow := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	subject := subject(ctx)
+	key := "daily:" + day + ":" + method + ":" + subject
+
+	count, err := opts.Counters.Add(ctx, key, 1, tomorrow)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "quota store unavailable: %v", err)
+	}
+	release := func() {
+		// The caller may be gone, but the count must still be returned.
+		if _, err := opts.Counters.Add(context.WithoutCancel(ctx), key, -1, tomorrow); err != nil {
+			logging.FromContext(ctx).WithError(err).Warn("Failed to release daily quota")
+		}
+	}
+	if count <= quota {
+		return release, nil
+	}
+
+	release()
+	return nil, exhausted(
+		fmt.Sprintf("daily quota of %d calls to %s exhausted", quota, method),
+		&errdetails.QuotaFailure_Violation{
+			Subject:     fmt.Sprintf("principal:%s method:%s day:%s", subject, method, day),
+			Description: fmt.Sprintf("at most %d calls per UTC day", quota),
+		},
+		tomorrow.Sub(now),
+	)
+}
+
+type itemQuotaKey struct{}
+
+// itemQuota is the quota the items of a stream are charged to.
+type itemQuota struct {
+	limiter *Limiter
+	method  string
+}
+
+// ReserveItem charges one item stored by a streaming call to the daily
+// quota set for it in ItemQuotas. The returned func gives the item back if
+// storing it fails. Calls without an item quota are not limited.
+func ReserveItem(ctx context.Context) (func(), error) {
+	q, ok := ctx.Value(itemQuotaKey{}).(*itemQuota)
+	if !ok {
+		return func() {}, nil
+	}
+	return q.limiter.reserveQuota(ctx, q.method)
+}
+
+// serverStream overrides the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func exhausted(message string, violation *errdetails.QuotaFailure_Violation, retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, message)
+	withDetails, err := st.WithDetails(
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{violation}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+func subject(ctx context.Context) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return principal.Subject
+	}
+	return "anonymous"
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
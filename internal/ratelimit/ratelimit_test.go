@@ -0,0 +1,291 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sabuhigr/grpc-demo/internal/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	createMethod = "/news.v1.NewsService/CreateNews"
+	getMethod    = "/news.v1.NewsService/GetNews"
+	bulkMethod   = "/news.v1.NewsService/BulkCreateNews"
+)
+
+// fakeClock is a clock that only moves when told to. It starts a minute
+// before midnight UTC, in the future so that counters do not expire early.
+type fakeClock struct {
+	t time.Time
+}
+
+func newLimiter(opts Options) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2100, 1, 1, 23, 59, 0, 0, time.UTC)}
+	l := New(opts)
+	l.now = func() time.Time { return clock.t }
+	return l, clock
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+// callerContext returns the context of a call by subject from ip.
+func callerContext(subject, ip string) context.Context {
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Subject: subject})
+	return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 4242}})
+}
+
+func callUnary(l *Limiter, ctx context.Context, method string, handlerErr error) error {
+	_, err := l.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+		return nil, handlerErr
+	})
+	return err
+}
+
+// exhaustedDetails checks that err is ResourceExhausted and returns its
+// QuotaFailure violation and retry delay.
+func exhaustedDetails(t *testing.T, err error) (*errdetails.QuotaFailure_Violation, time.Duration) {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("code %v, want ResourceExhausted (%v)", st.Code(), err)
+	}
+	var (
+		violation *errdetails.QuotaFailure_Violation
+		retry     *errdetails.RetryInfo
+	)
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.QuotaFailure:
+			if len(d.Violations) != 1 {
+				t.Fatalf("QuotaFailure has %d violations, want 1", len(d.Violations))
+			}
+			violation = d.Violations[0]
+		case *errdetails.RetryInfo:
+			retry = d
+		}
+	}
+	if violation == nil || retry == nil {
+		t.Fatalf("details %v, want QuotaFailure and RetryInfo", st.Details())
+	}
+	return violation, retry.RetryDelay.AsDuration()
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	l, clock := newLimiter(Options{Default: Limit{Rate: 2, Burst: 2}})
+	ctx := callerContext("alice", "10.0.0.1")
+
+	for i := range 2 {
+		if err := callUnary(l, ctx, getMethod, nil); err != nil {
+			t.Fatalf("call %d within burst: %v", i, err)
+		}
+	}
+	violation, wait := exhaustedDetails(t, callUnary(l, ctx, getMethod, nil))
+	if want := "principal:alice method:" + getMethod + " ip:10.0.0.1"; violation.Subject != want {
+		t.Errorf("violation subject %q, want %q", violation.Subject, want)
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("retry delay %v, want 500ms", wait)
+	}
+
+	clock.advance(250 * time.Millisecond)
+	if _, wait := exhaustedDetails(t, callUnary(l, ctx, getMethod, nil)); wait != 250*time.Millisecond {
+		t.Errorf("retry delay after half the wait %v, want 250ms", wait)
+	}
+	clock.advance(250 * time.Millisecond)
+	if err := callUnary(l, ctx, getMethod, nil); err != nil {
+		t.Fatalf("call after refill: %v", err)
+	}
+
+	// A long pause refills the bucket up to its burst, not beyond.
+	clock.advance(time.Minute)
+	for i := range 2 {
+		if err := callUnary(l, ctx, getMethod, nil); err != nil {
+			t.Fatalf("call %d after pause: %v", i, err)
+		}
+	}
+	exhaustedDetails(t, callUnary(l, ctx, getMethod, nil))
+}
+
+func TestBucketKeys(t *testing.T) {
+	l, _ := newLimiter(Options{
+		Default: Limit{Rate: 1, Burst: 1},
+		Methods: map[string]Limit{createMethod: {Rate: 1, Burst: 1}},
+	})
+	if err := callUnary(l, callerContext("alice", "10.0.0.1"), getMethod, nil); err != nil {
+		t.Fatal(err)
+	}
+	exhaustedDetails(t, callUnary(l, callerContext("alice", "10.0.0.1"), getMethod, nil))
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+	}{
+		{"other principal", callerContext("bob", "10.0.0.1"), getMethod},
+		{"other method", callerContext("alice", "10.0.0.1"), createMethod},
+		{"other ip", callerContext("alice", "10.0.0.2"), getMethod},
+		{"anonymous", peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1")}}), getMethod},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := callUnary(l, tt.ctx, tt.method, nil); err != nil {
+				t.Errorf("has its own bucket, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDailyQuota(t *testing.T) {
+	l, clock := newLimiter(Options{DailyQuotas: map[string]int64{createMethod: 2}})
+	ctx := callerContext("alice", "10.0.0.1")
+
+	if err := callUnary(l, ctx, createMethod, errors.New("store failed")); status.Code(err) == codes.ResourceExhausted {
+		t.Fatalf("failed call was limited: %v", err)
+	}
+	for i := range 2 {
+		if err := callUnary(l, ctx, createMethod, nil); err != nil {
+			t.Fatalf("call %d within quota (failed calls must not count): %v", i, err)
+		}
+	}
+	violation, wait := exhaustedDetails(t, callUnary(l, ctx, createMethod, nil))
+	if want := "principal:alice method:" + createMethod + " day:2100-01-01"; violation.Subject != want {
+		t.Errorf("violation subject %q, want %q", violation.Subject, want)
+	}
+	if wait != time.Minute {
+		t.Errorf("retry delay %v, want the minute left until midnight", wait)
+	}
+	if err := callUnary(l, callerContext("bob", "10.0.0.1"), createMethod, nil); err != nil {
+		t.Errorf("quota is per principal, got %v", err)
+	}
+	if err := callUnary(l, ctx, getMethod, nil); err != nil {
+		t.Errorf("quota is per method, got %v", err)
+	}
+
+	clock.advance(time.Minute)
+	for i := range 2 {
+		if err := callUnary(l, ctx, createMethod, nil); err != nil {
+			t.Fatalf("call %d on the next day: %v", i, err)
+		}
+	}
+	exhaustedDetails(t, callUnary(l, ctx, createMethod, nil))
+}
+
+func TestRefund(t *testing.T) {
+	l, _ := newLimiter(Options{DailyQuotas: map[string]int64{createMethod: 1}})
+	ctx := callerContext("alice", "10.0.0.1")
+	refunding := func(ctx context.Context, req any) (any, error) {
+		Refund(ctx)
+		Refund(ctx)
+		return nil, nil
+	}
+
+	for i := range 3 {
+		if _, err := l.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: createMethod}, refunding); err != nil {
+			t.Fatalf("refunded call %d: %v", i, err)
+		}
+	}
+	if err := callUnary(l, ctx, createMethod, nil); err != nil {
+		t.Fatalf("refunds used up the quota: %v", err)
+	}
+	exhaustedDetails(t, callUnary(l, ctx, createMethod, nil))
+
+	// Outside the limiter Refund does nothing.
+	Refund(context.Background())
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestReserveItem(t *testing.T) {
+	l, _ := newLimiter(Options{
+		DailyQuotas: map[string]int64{createMethod: 3},
+		ItemQuotas:  map[string]string{bulkMethod: createMethod},
+	})
+	ctx := callerContext("alice", "10.0.0.1")
+	if err := callUnary(l, ctx, createMethod, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var reserved, rejected int
+	err := l.Stream()(nil, &fakeStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: bulkMethod}, func(srv any, ss grpc.ServerStream) error {
+		var releases []func()
+		for range 4 {
+			release, err := ReserveItem(ss.Context())
+			if err != nil {
+				violation, _ := exhaustedDetails(t, err)
+				if !strings.Contains(violation.Subject, "method:"+createMethod) {
+					t.Errorf("item charged to %q, want %s", violation.Subject, createMethod)
+				}
+				rejected++
+				continue
+			}
+			releases = append(releases, release)
+			reserved++
+		}
+		// The first item fails to store and is given back.
+		releases[0]()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if reserved != 2 || rejected != 2 {
+		t.Errorf("reserved %d and rejected %d items, want 2 and 2", reserved, rejected)
+	}
+	if err := callUnary(l, ctx, createMethod, nil); err != nil {
+		t.Fatalf("released item was not given back: %v", err)
+	}
+	exhaustedDetails(t, callUnary(l, ctx, createMethod, nil))
+
+	if _, err := ReserveItem(ctx); err != nil {
+		t.Errorf("calls without an item quota are not limited, got %v", err)
+	}
+}
+
+func TestSetOptions(t *testing.T) {
+	counters := NewMemoryCounters()
+	l, _ := newLimiter(Options{
+		Default:     Limit{Rate: 1, Burst: 1},
+		DailyQuotas: map[string]int64{createMethod: 1},
+		Counters:    counters,
+	})
+	ctx := callerContext("alice", "10.0.0.1")
+	if err := callUnary(l, ctx, createMethod, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := callUnary(l, ctx, getMethod, nil); err != nil {
+		t.Fatal(err)
+	}
+	exhaustedDetails(t, callUnary(l, ctx, getMethod, nil))
+	exhaustedDetails(t, callUnary(l, ctx, createMethod, nil))
+
+	l.SetOptions(Options{DailyQuotas: map[string]int64{createMethod: 2}})
+	if got := l.opts.Load().Counters; got != counters {
+		t.Error("SetOptions without Counters replaced the counter store")
+	}
+	if err := callUnary(l, ctx, getMethod, nil); err != nil {
+		t.Errorf("limit removed on reload, got %v", err)
+	}
+	// The count carries over: one more call fits the raised quota.
+	if err := callUnary(l, ctx, createMethod, nil); err != nil {
+		t.Fatalf("raised quota: %v", err)
+	}
+	exhaustedDetails(t, callUnary(l, ctx, createMethod, nil))
+}
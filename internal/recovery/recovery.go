@@ -0,0 +1,91 @@
+// Package recovery turns panics in handlers and interceptors into Internal
+// errors instead of crashing the server.
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sabuhigr/grpc-demo/internal/logging"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor recovers panics of the calls it wraps.
+type Interceptor struct {
+	debug   atomic.Bool
+	onPanic []func(method string)
+}
+
+// New returns an interceptor. With debug set, the panic value and stack are
+// sent to the caller; otherwise only the request id is.
+func New(debug bool) *Interceptor {
+	i := &Interceptor{}
+	i.debug.Store(debug)
+	return i
+}
+
+// SetDebug switches sending panic details to callers on or off.
+func (i *Interceptor) SetDebug(debug bool) {
+	i.debug.Store(debug)
+}
+
+// OnPanic registers fn to be called with the full method name of every call
+// that panicked, e.g. to count them.
+func (i *Interceptor) OnPanic(fn func(method string)) *Interceptor {
+	i.onPanic = append(i.onPanic, fn)
+	return i
+}
+
+// Unary returns the interceptor for unary calls. It must run after the
+// logging interceptor so the stack is logged with the request id.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				resp, err = nil, i.recovered(ctx, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the interceptor for streaming calls.
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = i.recovered(ss.Context(), info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func (i *Interceptor) recovered(ctx context.Context, method string, r any) error {
+	stack := string(debug.Stack())
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"panic": fmt.Sprint(r),
+		"stack": stack,
+	}).Error("Recovered from panic")
+	for _, fn := range i.onPanic {
+		fn(method)
+	}
+
+	info := &errdetails.DebugInfo{Detail: "request id " + logging.RequestID(ctx)}
+	if i.debug.Load() {
+		info.Detail = fmt.Sprintf("panic: %v (request id %s)", r, logging.RequestID(ctx))
+		info.StackEntries = strings.Split(strings.TrimSpace(stack), "\n")
+	}
+	st, err := status.New(codes.Internal, "internal error").WithDetails(info)
+	if err != nil {
+		return status.Error(codes.Internal, "internal error")
+	}
+	return st.Err()
+}
@@ -0,0 +1,456 @@
+// Package storetest checks that a NewsStorer behaves like memstore, the
+// reference implementation. Each backend runs the suite from its own tests.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	ingrpc "github.com/sabuhigr/grpc-demo/internal/grpc"
+	"github.com/sabuhigr/grpc-demo/internal/memstore"
+)
+
+// Opener returns an empty store for one test. It registers the cleanup of
+// the store with t.
+type Opener func(t *testing.T) ingrpc.NewsStorer
+
+// Run runs the behavioural suite against the stores open returns.
+func Run(t *testing.T, open Opener) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s ingrpc.NewsStorer)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"CreateRetry", testCreateRetry},
+		{"CreateBatch", testCreateBatch},
+		{"CreateBatchAllOrNothing", testCreateBatchAllOrNothing},
+		{"Update", testUpdate},
+		{"DeleteAndRestore", testDeleteAndRestore},
+		{"Purge", testPurge},
+		{"GetAll", testGetAll},
+		{"Query", testQuery},
+		{"QueryPages", testQueryPages},
+		{"Watch", testWatch},
+		{"CanceledContext", testCanceledContext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, open(t))
+		})
+	}
+}
+
+// NewNews returns valid news by author with the tags.
+func NewNews(author string, tags ...string) *memstore.News {
+	id := uuid.New()
+	return &memstore.News{
+		ID:      id,
+		Author:  author,
+		Title:   "title " + id.String(),
+		Summary: "summary",
+		Content: "content",
+		Source:  &url.URL{Scheme: "https", Host: "example.com", Path: "/" + id.String()},
+		Tags:    tags,
+	}
+}
+
+// Create stores news, failing the test on error.
+func Create(t *testing.T, s ingrpc.NewsStorer, news *memstore.News) *memstore.News {
+	t.Helper()
+	created, err := s.Create(context.Background(), news)
+	if err != nil {
+		t.Fatalf("Create(%s): %v", news.ID, err)
+	}
+	return created
+}
+
+// Equal reports how got differs from want in the stored fields, or "" if
+// they match.
+func Equal(got, want *memstore.News) string {
+	switch {
+	case got.ID != want.ID:
+		return fmt.Sprintf("id %s, want %s", got.ID, want.ID)
+	case got.Author != want.Author || got.Title != want.Title || got.Summary != want.Summary || got.Content != want.Content:
+		return fmt.Sprintf("text fields %q/%q/%q/%q, want %q/%q/%q/%q",
+			got.Author, got.Title, got.Summary, got.Content, want.Author, want.Title, want.Summary, want.Content)
+	case got.Source.String() != want.Source.String():
+		return fmt.Sprintf("source %s, want %s", got.Source, want.Source)
+	case !slices.Equal(got.Tags, want.Tags):
+		return fmt.Sprintf("tags %v, want %v", got.Tags, want.Tags)
+	case !got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) || !got.DeletedAt.Equal(want.DeletedAt):
+		return fmt.Sprintf("times %v/%v/%v, want %v/%v/%v",
+			got.CreatedAt, got.UpdatedAt, got.DeletedAt, want.CreatedAt, want.UpdatedAt, want.DeletedAt)
+	}
+	return ""
+}
+
+func wantErr(t *testing.T, what string, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Fatalf("%s: got error %v, want %v", what, err, target)
+	}
+}
+
+func testCreateAndGet(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	news := NewNews("alice", "go", "grpc")
+	created := Create(t, s, news)
+	if created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() || !created.DeletedAt.IsZero() {
+		t.Fatalf("created news has times %v/%v/%v", created.CreatedAt, created.UpdatedAt, created.DeletedAt)
+	}
+
+	got, err := s.Get(ctx, news.ID, false)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if diff := Equal(got, created); diff != "" {
+		t.Errorf("Get: %s", diff)
+	}
+
+	_, err = s.Get(ctx, uuid.New(), true)
+	wantErr(t, "Get unknown id", err, memstore.ErrNotFound)
+}
+
+func testCreateRetry(t *testing.T, s ingrpc.NewsStorer) {
+	news := NewNews("alice")
+	created := Create(t, s, news)
+
+	retried, err := s.Create(context.Background(), news)
+	if err != nil {
+		t.Fatalf("Create with the same content: %v", err)
+	}
+	if diff := Equal(retried, created); diff != "" {
+		t.Errorf("Create with the same content: %s", diff)
+	}
+
+	changed := *news
+	changed.Title = "another title"
+	_, err = s.Create(context.Background(), &changed)
+	wantErr(t, "Create with other content", err, memstore.ErrAlreadyExists)
+}
+
+func testCreateBatch(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	existing := Create(t, s, NewNews("alice"))
+	taken := *existing
+	taken.Title = "another title"
+	fresh := NewNews("bob")
+
+	results, err := s.CreateBatch(ctx, []*memstore.News{fresh, &taken, fresh}, false)
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("CreateBatch returned %d results, want 3", len(results))
+	}
+	if results[0].Err != nil {
+		t.Errorf("new item: %v", results[0].Err)
+	}
+	wantErr(t, "taken id", results[1].Err, memstore.ErrAlreadyExists)
+	wantErr(t, "id repeated in the batch", results[2].Err, memstore.ErrAlreadyExists)
+
+	if _, err := s.Get(ctx, fresh.ID, false); err != nil {
+		t.Errorf("Get new item: %v", err)
+	}
+	got, err := s.Get(ctx, existing.ID, false)
+	if err != nil {
+		t.Fatalf("Get taken item: %v", err)
+	}
+	if diff := Equal(got, existing); diff != "" {
+		t.Errorf("taken item changed: %s", diff)
+	}
+}
+
+func testCreateBatchAllOrNothing(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	existing := Create(t, s, NewNews("alice"))
+	taken := *existing
+	taken.Title = "another title"
+	fresh := NewNews("bob")
+
+	results, err := s.CreateBatch(ctx, []*memstore.News{fresh, &taken}, true)
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	wantErr(t, "valid item", results[0].Err, memstore.ErrBatchAborted)
+	wantErr(t, "taken id", results[1].Err, memstore.ErrAlreadyExists)
+	_, err = s.Get(ctx, fresh.ID, true)
+	wantErr(t, "Get aborted item", err, memstore.ErrNotFound)
+}
+
+func testUpdate(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	created := Create(t, s, NewNews("alice", "go"))
+
+	patch := &memstore.News{ID: created.ID, Title: "new title", Summary: "ignored", Tags: []string{"rust"}}
+	updated, err := s.Update(ctx, patch, []string{memstore.FieldTitle, memstore.FieldTags})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	want := *created
+	want.Title, want.Tags, want.UpdatedAt = "new title", []string{"rust"}, updated.UpdatedAt
+	if diff := Equal(updated, &want); diff != "" {
+		t.Errorf("Update: %s", diff)
+	}
+	if updated.UpdatedAt.Before(created.UpdatedAt) {
+		t.Errorf("UpdatedAt went back from %v to %v", created.UpdatedAt, updated.UpdatedAt)
+	}
+
+	got, err := s.Get(ctx, created.ID, false)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if diff := Equal(got, updated); diff != "" {
+		t.Errorf("Get after Update: %s", diff)
+	}
+
+	_, err = s.Update(ctx, &memstore.News{ID: uuid.New()}, []string{memstore.FieldTitle})
+	wantErr(t, "Update unknown id", err, memstore.ErrNotFound)
+}
+
+func testDeleteAndRestore(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	created := Create(t, s, NewNews("alice"))
+
+	deleted, err := s.Delete(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if deleted.DeletedAt.IsZero() {
+		t.Fatal("Delete left DeletedAt unset")
+	}
+	_, err = s.Get(ctx, created.ID, false)
+	wantErr(t, "Get deleted news", err, memstore.ErrNotFound)
+	tombstone, err := s.Get(ctx, created.ID, true)
+	if err != nil {
+		t.Fatalf("Get deleted news with includeDeleted: %v", err)
+	}
+	if diff := Equal(tombstone, deleted); diff != "" {
+		t.Errorf("tombstone: %s", diff)
+	}
+	_, err = s.Delete(ctx, created.ID)
+	wantErr(t, "Delete twice", err, memstore.ErrNotFound)
+	_, err = s.Update(ctx, &memstore.News{ID: created.ID, Title: "x"}, []string{memstore.FieldTitle})
+	wantErr(t, "Update deleted news", err, memstore.ErrNotFound)
+
+	restored, err := s.Restore(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if !restored.DeletedAt.IsZero() {
+		t.Errorf("Restore left DeletedAt %v", restored.DeletedAt)
+	}
+	if _, err := s.Get(ctx, created.ID, false); err != nil {
+		t.Errorf("Get restored news: %v", err)
+	}
+	_, err = s.Restore(ctx, created.ID)
+	wantErr(t, "Restore live news", err, memstore.ErrConflict)
+	_, err = s.Restore(ctx, uuid.New())
+	wantErr(t, "Restore unknown id", err, memstore.ErrNotFound)
+}
+
+func testPurge(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	live := Create(t, s, NewNews("alice"))
+	deleted := Create(t, s, NewNews("alice"))
+	if _, err := s.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	for _, id := range []uuid.UUID{live.ID, deleted.ID} {
+		if err := s.Purge(ctx, id); err != nil {
+			t.Fatalf("Purge(%s): %v", id, err)
+		}
+		_, err := s.Get(ctx, id, true)
+		wantErr(t, "Get purged news", err, memstore.ErrNotFound)
+		wantErr(t, "Purge twice", s.Purge(ctx, id), memstore.ErrNotFound)
+	}
+
+	// A purged id is free again.
+	Create(t, s, &memstore.News{ID: live.ID, Author: "bob", Title: "t", Source: live.Source})
+}
+
+func testGetAll(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	var created []*memstore.News
+	for range 5 {
+		created = append(created, Create(t, s, NewNews("alice")))
+	}
+	if _, err := s.Delete(ctx, created[1].ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	live, err := s.GetAll(ctx, false)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if got, want := ids(live), ids(slices.Delete(slices.Clone(created), 1, 2)); !slices.Equal(got, want) {
+		t.Errorf("GetAll = %v, want %v in creation order", got, want)
+	}
+
+	all, err := s.GetAll(ctx, true)
+	if err != nil {
+		t.Fatalf("GetAll with deleted: %v", err)
+	}
+	if got, want := ids(all), ids(created); !slices.Equal(got, want) {
+		t.Errorf("GetAll with deleted = %v, want %v", got, want)
+	}
+}
+
+func testQuery(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	a1 := Create(t, s, NewNews("alice", "go", "grpc"))
+	a2 := Create(t, s, NewNews("alice", "rust"))
+	b1 := Create(t, s, NewNews("bob", "go"))
+	b2 := Create(t, s, NewNews("bob", "go", "grpc"))
+	if _, err := s.Delete(ctx, b2.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query memstore.Query
+		want  []*memstore.News
+	}{
+		{"everything", memstore.Query{}, []*memstore.News{a1, a2, b1}},
+		{"author", memstore.Query{Author: "alice"}, []*memstore.News{a1, a2}},
+		{"any tag", memstore.Query{TagsAny: []string{"rust", "grpc"}}, []*memstore.News{a1, a2}},
+		{"all tags", memstore.Query{TagsAll: []string{"go", "grpc"}}, []*memstore.News{a1}},
+		{"author and tag", memstore.Query{Author: "bob", TagsAny: []string{"go"}}, []*memstore.News{b1}},
+		{"deleted", memstore.Query{TagsAll: []string{"go", "grpc"}, IncludeDeleted: true}, []*memstore.News{a1, b2}},
+		{"descending", memstore.Query{Author: "alice", Descending: true}, []*memstore.News{a2, a1}},
+		{"host", memstore.Query{SourceHost: "EXAMPLE.com", Author: "bob"}, []*memstore.News{b1}},
+		{"other host", memstore.Query{SourceHost: "example.org"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Limit = 10
+			got, more, err := s.Query(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			if more {
+				t.Error("Query reported more results")
+			}
+			if !slices.Equal(ids(got), ids(tt.want)) {
+				t.Errorf("Query = %v, want %v", ids(got), ids(tt.want))
+			}
+		})
+	}
+}
+
+func testQueryPages(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	var created []*memstore.News
+	for range 7 {
+		created = append(created, Create(t, s, NewNews("alice", "go")))
+	}
+
+	for _, q := range []memstore.Query{
+		{Limit: 3},
+		{Limit: 3, TagsAny: []string{"go"}},
+		{Limit: 3, OrderBy: memstore.OrderByTitle},
+	} {
+		var got []*memstore.News
+		for page := 0; ; page++ {
+			if page > len(created) {
+				t.Fatalf("query %+v never ends", q)
+			}
+			items, more, err := s.Query(ctx, q)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			got = append(got, items...)
+			if !more {
+				break
+			}
+			cursor := memstore.CursorFor(items[len(items)-1], q.OrderBy)
+			q.After = &cursor
+		}
+
+		want := slices.Clone(created)
+		if q.OrderBy == memstore.OrderByTitle {
+			slices.SortFunc(want, func(a, b *memstore.News) int { return strings.Compare(a.Title, b.Title) })
+		}
+		if !slices.Equal(ids(got), ids(want)) {
+			t.Errorf("pages of %+v = %v, want %v", q, ids(got), ids(want))
+		}
+	}
+}
+
+func testWatch(t *testing.T, s ingrpc.NewsStorer) {
+	ctx := context.Background()
+	sub, err := s.Watch(ctx, memstore.WatchFilter{Author: "alice"}, "")
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer sub.Close()
+
+	news := Create(t, s, NewNews("alice"))
+	Create(t, s, NewNews("bob"))
+	if _, err := s.Delete(ctx, news.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	created := nextEvent(t, sub)
+	if created.Type != memstore.EventCreated || created.News.ID != news.ID {
+		t.Fatalf("first event %v on %s, want created on %s", created.Type, created.News.ID, news.ID)
+	}
+	if deleted := nextEvent(t, sub); deleted.Type != memstore.EventDeleted || deleted.News.ID != news.ID {
+		t.Fatalf("second event %v on %s, want deleted on %s", deleted.Type, deleted.News.ID, news.ID)
+	}
+
+	resumed, err := s.Watch(ctx, memstore.WatchFilter{Author: "alice"}, created.ResumeToken)
+	if err != nil {
+		t.Fatalf("Watch from resume token: %v", err)
+	}
+	defer resumed.Close()
+	if replayed := nextEvent(t, resumed); replayed.Type != memstore.EventDeleted || replayed.News.ID != news.ID {
+		t.Errorf("replayed event %v on %s, want deleted on %s", replayed.Type, replayed.News.ID, news.ID)
+	}
+
+	_, err = s.Watch(ctx, memstore.WatchFilter{}, "not a token")
+	wantErr(t, "Watch from a malformed token", err, memstore.ErrInvalidResumeToken)
+}
+
+func testCanceledContext(t *testing.T, s ingrpc.NewsStorer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	news := NewNews("alice")
+	_, err := s.Create(ctx, news)
+	wantErr(t, "Create", err, context.Canceled)
+	_, err = s.Get(context.Background(), news.ID, true)
+	wantErr(t, "Get after a canceled Create", err, memstore.ErrNotFound)
+	_, _, err = s.Query(ctx, memstore.Query{Limit: 1})
+	wantErr(t, "Query", err, context.Canceled)
+}
+
+func nextEvent(t *testing.T, sub *memstore.Subscription) memstore.Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events():
+		if !ok {
+			t.Fatalf("subscription ended: %v", sub.Err())
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event within 5s")
+		return memstore.Event{}
+	}
+}
+
+func ids(news []*memstore.News) []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(news))
+	for _, n := range news {
+		out = append(out, n.ID)
+	}
+	return out
+}
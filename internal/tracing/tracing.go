@@ -0,0 +1,89 @@
+// Package tracing sets up OpenTelemetry tracing with W3C trace context
+// propagation. Spans are exported to stdout or to a local file, so traces
+// can be inspected without a collector.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Options configures Setup.
+type Options struct {
+	ServiceName string
+	// Exporter is ExporterNone, ExporterStdout or ExporterFile.
+	Exporter string
+	// File receives the spans of the file exporter as OTLP JSON, one export
+	// request per line.
+	File string
+	// SampleRatio is the fraction of new traces that are recorded. Spans
+	// follow the decision of their parent.
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and propagator. The returned
+// func flushes pending spans and must be called before exiting. With
+// ExporterNone, trace context is still propagated but nothing is recorded.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch opts.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		e, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, err
+		}
+		exporter = e
+	case ExporterFile:
+		if opts.File == "" {
+			return nil, fmt.Errorf("the %s exporter needs a file", ExporterFile)
+		}
+		e, err := otlptrace.New(ctx, newFileClient(opts.File))
+		if err != nil {
+			return nil, err
+		}
+		exporter = e
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, want %s, %s or %s", opts.Exporter, ExporterNone, ExporterStdout, ExporterFile)
+	}
+	if opts.SampleRatio < 0 || opts.SampleRatio > 1 {
+		return nil, fmt.Errorf("sample ratio %g is not within [0, 1]", opts.SampleRatio)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -1,9 +1,5 @@
 package types
 
-const (
-	Static_token = "dwwf=Adowwlfpfwpifwpwpw27O!"
-)
-
 type ErrDetails struct {
 	Code        int    `json:"code"`
 	Message     string `json:"message"`